@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/morelj/gtl/internal/function"
 )
@@ -93,7 +94,9 @@ func createTemplate(name string) *template.Template {
 	for i := range function.Functions {
 		maps.Copy(funcs, function.Functions[i].Functions)
 	}
-	return template.New(name).Funcs(funcs)
+	tmpl := template.New(name).Funcs(funcs)
+	function.SetTemplate(tmpl)
+	return tmpl
 }
 
 func loadTemplate(source string) *template.Template {
@@ -111,7 +114,8 @@ func loadTemplate(source string) *template.Template {
 		return tmpl
 	}
 
-	// Load from the file
+	// Load from the file, data files are then relative to its directory
+	function.SetBaseDir(filepath.Dir(source))
 	tmpl, err := tmpl.ParseFiles(source)
 	if err != nil {
 		panic(err.Error())
@@ -160,6 +164,8 @@ func main() {
 	dataFiles := flag.String("d", "", fmt.Sprintf("A list of JSON files to load as data, separated with %c", os.PathListSeparator))
 	var dataInline multiStringValueFlag
 	flag.Var(&dataInline, "D", "An inline JSON to expose to the template as data (can appear more than once)")
+	nowTime := flag.String("now", "", "Use the given RFC3339 time as the current time, so that the time functions produce the same output on every run")
+	seed := flag.String("seed", "", "Seed the random functions, so that they produce the same output on every run")
 	version := flag.Bool("version", false, "Show the version number and quit")
 	flag.Parse()
 
@@ -172,6 +178,17 @@ func main() {
 		panic("-i and -t are mutually exclusive")
 	}
 
+	if *nowTime != "" {
+		t, err := time.Parse(time.RFC3339, *nowTime)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid value for -now: %s", *nowTime))
+		}
+		function.SetNow(t)
+	}
+	if *seed != "" {
+		function.SetSeed(*seed)
+	}
+
 	env := buildEnvironment(*dataFiles, dataInline)
 
 	var tmpl *template.Template
@@ -182,6 +199,12 @@ func main() {
 		tmpl = loadTemplate(*templateFile)
 	}
 
+	// Render to memory first, so that nothing is written if rendering fails (e.g. because of a missing key)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, env); err != nil {
+		panic(err.Error())
+	}
+
 	out := os.Stdout
 	if *outputFile != "-" {
 		file, err := os.Create(*outputFile)
@@ -192,7 +215,7 @@ func main() {
 		defer file.Close()
 	}
 
-	if err := tmpl.Execute(out, env); err != nil {
+	if _, err := buf.WriteTo(out); err != nil {
 		panic(err.Error())
 	}
 }
@@ -1,3 +1,13 @@
 module github.com/morelj/gtl
 
-go 1.23
+go 1.23.0
+
+require (
+	filippo.io/age v1.2.1
+	github.com/BurntSushi/toml v1.6.0
+	github.com/jmespath/go-jmespath v0.4.1-0.20220621161143-b0104c826a24
+	golang.org/x/crypto v0.41.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.35.0 // indirect
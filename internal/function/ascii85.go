@@ -0,0 +1,39 @@
+package function
+
+import (
+	"encoding/ascii85"
+	"text/template"
+)
+
+const ascii85Category = "Ascii85"
+
+var ascii85Funcs = []FunctionSet{
+	{
+		Category:    ascii85Category,
+		Syntax:      "ascii85_encode <val string|Bytes>",
+		Description: []string{"Encodes val in Ascii85, as used in PostScript and PDF (without the <~ ~> delimiters)"},
+		Functions: template.FuncMap{"ascii85_encode": encoder(func(v []byte) string {
+			data := make([]byte, ascii85.MaxEncodedLen(len(v)))
+			n := ascii85.Encode(data, v)
+			return string(data[:n])
+		})},
+	},
+	{
+		Category: ascii85Category,
+		Syntax:   "ascii85_decode[_bytes] <val string|Bytes>",
+		Description: []string{
+			"Decodes val from Ascii85. The _bytes variant returns Bytes instead of a string.",
+			"Returns an error if val is not valid Ascii85",
+		},
+		Functions: template.FuncMap{
+			"ascii85_decode":       stringDecoder(ascii85Decode),
+			"ascii85_decode_bytes": bytesDecoder(ascii85Decode),
+		},
+	},
+}
+
+func ascii85Decode(v []byte) ([]byte, error) {
+	data := make([]byte, 4*len(v))
+	n, _, err := ascii85.Decode(data, v, true)
+	return data[:n], err
+}
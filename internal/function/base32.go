@@ -0,0 +1,52 @@
+package function
+
+import (
+	"encoding/base32"
+	"text/template"
+)
+
+const base32Category = "Base32"
+
+var base32Funcs = []FunctionSet{
+	{
+		Category: base32Category,
+		Syntax:   "base32[_hex][_raw]_encode <val string|Bytes>",
+		Description: []string{
+			"Encodes val in Base32. This function comes in several variants by adding the _hex and _raw tags.",
+			"_raw variants remove the = padding characters, and _hex variants use the \"Extended Hex\" alphabet",
+		},
+		Functions: template.FuncMap{
+			"base32_encode":         encoder(base32.StdEncoding.EncodeToString),
+			"base32_raw_encode":     encoder(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString),
+			"base32_hex_encode":     encoder(base32.HexEncoding.EncodeToString),
+			"base32_hex_raw_encode": encoder(base32.HexEncoding.WithPadding(base32.NoPadding).EncodeToString),
+		},
+	},
+	{
+		Category: base32Category,
+		Syntax:   "base32[_hex][_raw]_decode[_bytes] <val string|Bytes>",
+		Description: []string{
+			"Decodes val from Base32. This function comes in several variants by adding the _hex and _raw tags.",
+			"_raw variants remove the = padding characters, and _hex variants use the \"Extended Hex\" alphabet.",
+			"_bytes variants return Bytes instead of a string. Returns an error if val is not valid Base32",
+		},
+		Functions: template.FuncMap{
+			"base32_decode":               stringDecoder(base32Decoder(base32.StdEncoding)),
+			"base32_raw_decode":           stringDecoder(base32Decoder(base32.StdEncoding.WithPadding(base32.NoPadding))),
+			"base32_hex_decode":           stringDecoder(base32Decoder(base32.HexEncoding)),
+			"base32_hex_raw_decode":       stringDecoder(base32Decoder(base32.HexEncoding.WithPadding(base32.NoPadding))),
+			"base32_decode_bytes":         bytesDecoder(base32Decoder(base32.StdEncoding)),
+			"base32_raw_decode_bytes":     bytesDecoder(base32Decoder(base32.StdEncoding.WithPadding(base32.NoPadding))),
+			"base32_hex_decode_bytes":     bytesDecoder(base32Decoder(base32.HexEncoding)),
+			"base32_hex_raw_decode_bytes": bytesDecoder(base32Decoder(base32.HexEncoding.WithPadding(base32.NoPadding))),
+		},
+	},
+}
+
+func base32Decoder(e *base32.Encoding) func([]byte) ([]byte, error) {
+	return func(v []byte) ([]byte, error) {
+		data := make([]byte, e.DecodedLen(len(v)))
+		n, err := e.Decode(data, v)
+		return data[:n], err
+	}
+}
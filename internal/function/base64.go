@@ -10,54 +10,43 @@ const base64Category = "Base64"
 var base64Funcs = []FunctionSet{
 	{
 		Category: base64Category,
-		Syntax:   "base64[_url][_raw]_encode <val string>",
+		Syntax:   "base64[_url][_raw]_encode <val string|Bytes>",
 		Description: []string{
 			"Encodes val in Base64. This function comes in several variants by adding the _url and _raw tags.",
 			"_raw variants remove the = padding characters, and _url variants use the alternate URL compliant alphabet",
 		},
 		Functions: template.FuncMap{
-			"base64_encode": func(v string) string {
-				return base64.StdEncoding.EncodeToString(([]byte)(v))
-			},
-			"base64_raw_encode": func(v string) string {
-				return base64.RawStdEncoding.EncodeToString(([]byte)(v))
-			},
-			"base64_url_encode": func(v string) string {
-				return base64.URLEncoding.EncodeToString(([]byte)(v))
-			},
-			"base64_raw_url_encode": func(v string) string {
-				return base64.RawURLEncoding.EncodeToString(([]byte)(v))
-			},
+			"base64_encode":         encoder(base64.StdEncoding.EncodeToString),
+			"base64_raw_encode":     encoder(base64.RawStdEncoding.EncodeToString),
+			"base64_url_encode":     encoder(base64.URLEncoding.EncodeToString),
+			"base64_raw_url_encode": encoder(base64.RawURLEncoding.EncodeToString),
 		},
 	},
 	{
 		Category: base64Category,
-		Syntax:   "base64[_url][_raw]_decode <val string>",
+		Syntax:   "base64[_url][_raw]_decode[_bytes] <val string|Bytes>",
 		Description: []string{
 			"Decodes val from Base64. This function comes in several variants by adding the _url and _raw tags.",
-			"_raw variants remove the = padding characters, and _url variants use the alternate URL compliant alphabet",
+			"_raw variants remove the = padding characters, and _url variants use the alternate URL compliant alphabet.",
+			"_bytes variants return Bytes instead of a string. Returns an error if val is not valid Base64",
 		},
 		Functions: template.FuncMap{
-			"base64_decode": func(v string) string {
-				return base64Decode(v, base64.StdEncoding)
-			},
-			"base64_raw_decode": func(v string) string {
-				return base64Decode(v, base64.RawStdEncoding)
-			},
-			"base64_url_decode": func(v string) string {
-				return base64Decode(v, base64.URLEncoding)
-			},
-			"base64_raw_url_decode": func(v string) string {
-				return base64Decode(v, base64.RawURLEncoding)
-			},
+			"base64_decode":               stringDecoder(base64Decoder(base64.StdEncoding)),
+			"base64_raw_decode":           stringDecoder(base64Decoder(base64.RawStdEncoding)),
+			"base64_url_decode":           stringDecoder(base64Decoder(base64.URLEncoding)),
+			"base64_raw_url_decode":       stringDecoder(base64Decoder(base64.RawURLEncoding)),
+			"base64_decode_bytes":         bytesDecoder(base64Decoder(base64.StdEncoding)),
+			"base64_raw_decode_bytes":     bytesDecoder(base64Decoder(base64.RawStdEncoding)),
+			"base64_url_decode_bytes":     bytesDecoder(base64Decoder(base64.URLEncoding)),
+			"base64_raw_url_decode_bytes": bytesDecoder(base64Decoder(base64.RawURLEncoding)),
 		},
 	},
 }
 
-func base64Decode(v string, e *base64.Encoding) string {
-	data, err := e.DecodeString(v)
-	if err != nil {
-		panic(err)
+func base64Decoder(e *base64.Encoding) func([]byte) ([]byte, error) {
+	return func(v []byte) ([]byte, error) {
+		data := make([]byte, e.DecodedLen(len(v)))
+		n, err := e.Decode(data, v)
+		return data[:n], err
 	}
-	return string(data)
 }
@@ -0,0 +1,88 @@
+package function
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// Bytes is a binary value. It is written as is to the output, and accepted by all the functions taking binary
+// data, so that binary content can go through decoding, hashing and encoding functions without any conversion.
+type Bytes []byte
+
+// String returns the raw content of b, which is what text/template writes to the output.
+func (b Bytes) String() string {
+	return string(b)
+}
+
+const bytesCategory = "Bytes"
+
+var bytesFuncs = []FunctionSet{
+	{
+		Category:    bytesCategory,
+		Syntax:      "to_bytes <val string>",
+		Description: []string{"Converts val to Bytes, a binary value which is written as is to the output"},
+		Functions: template.FuncMap{"to_bytes": func(v any) (Bytes, error) {
+			return toBytes(v)
+		}},
+	},
+	{
+		Category:    bytesCategory,
+		Syntax:      "to_string <val any>",
+		Description: []string{"Converts val to a string. Bytes are converted without any change to their content"},
+		Functions: template.FuncMap{"to_string": func(v any) string {
+			if v == nil {
+				return ""
+			}
+			return fmt.Sprint(v)
+		}},
+	},
+}
+
+// toBytes returns the content of v, which must be a string or a binary value
+func toBytes(v any) (Bytes, error) {
+	switch v := v.(type) {
+	case string:
+		return Bytes(v), nil
+	case Bytes:
+		return v, nil
+	case []byte:
+		return Bytes(v), nil
+	default:
+		return nil, fmt.Errorf("Unsupported type: %T", v)
+	}
+}
+
+// encoder returns a template function which applies f to a string or binary value
+func encoder(f func([]byte) string) func(v any) (string, error) {
+	return func(v any) (string, error) {
+		data, err := toBytes(v)
+		if err != nil {
+			return "", err
+		}
+		return f(data), nil
+	}
+}
+
+// stringDecoder returns a template function which applies f to a string or binary value and returns the result as
+// a string
+func stringDecoder(f func([]byte) ([]byte, error)) func(v any) (string, error) {
+	return func(v any) (string, error) {
+		data, err := bytesDecoder(f)(v)
+		return string(data), err
+	}
+}
+
+// bytesDecoder returns a template function which applies f to a string or binary value and returns the result as
+// Bytes
+func bytesDecoder(f func([]byte) ([]byte, error)) func(v any) (Bytes, error) {
+	return func(v any) (Bytes, error) {
+		data, err := toBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = f(data); err != nil {
+			return nil, err
+		}
+		return Bytes(data), nil
+	}
+}
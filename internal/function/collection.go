@@ -0,0 +1,266 @@
+package function
+
+import (
+	"fmt"
+	"reflect"
+	"text/template"
+)
+
+var collectionFuncs = []FunctionSet{
+	{
+		Category: mapSliceCategory,
+		Syntax:   "pluck <path string> <s []any>",
+		Description: []string{
+			"Returns a slice containing the value found at path in each element of s (see filter_path for the path syntax).",
+			"Elements where path does not exist are skipped",
+		},
+		Functions: template.FuncMap{"pluck": func(path string, s any) ([]any, error) {
+			elements, err := parsePath(path)
+			if err != nil {
+				return nil, err
+			}
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]any, 0, len(v))
+			for _, e := range v {
+				if value, ok := lookupPath(e, elements); ok {
+					ret = append(ret, value)
+				}
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "group_by <path string> <s []any>",
+		Description: []string{
+			"Groups the elements of s on the value found at path, and returns a map of this value (as a string) to the list of matching elements.",
+			"Elements keep their relative order. Elements where path does not exist are skipped",
+		},
+		Functions: template.FuncMap{"group_by": func(path string, s any) (map[string]any, error) {
+			elements, err := parsePath(path)
+			if err != nil {
+				return nil, err
+			}
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			groups := make(map[string]any)
+			for _, e := range v {
+				if value, ok := lookupPath(e, elements); ok {
+					k := fmt.Sprint(value)
+					group, _ := groups[k].([]any)
+					groups[k] = append(group, e)
+				}
+			}
+			return groups, nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "uniq <s []any>",
+		Description: []string{"Returns a copy of s without duplicate values, keeping the first occurrence of each value. Numbers are compared by value"},
+		Functions: template.FuncMap{"uniq": func(s any) ([]any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]any, 0, len(v))
+			for _, e := range v {
+				if indexOf(e, ret) < 0 {
+					ret = append(ret, e)
+				}
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "flatten <s []any>",
+		Description: []string{"Returns a slice containing the elements of s, with nested slices recursively replaced by their elements"},
+		Functions: template.FuncMap{"flatten": func(s any) ([]any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			return flatten(make([]any, 0, len(v)), v), nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "chunk <size int> <s []any>",
+		Description: []string{"Splits s into slices of size elements. The last slice may be shorter"},
+		Functions: template.FuncMap{"chunk": func(size int, s any) ([]any, error) {
+			if size <= 0 {
+				return nil, fmt.Errorf("Invalid chunk size: %d", size)
+			}
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			chunks := make([]any, 0, (len(v)+size-1)/size)
+			for i := 0; i < len(v); i += size {
+				chunks = append(chunks, append([]any{}, v[i:min(i+size, len(v))]...))
+			}
+			return chunks, nil
+		}},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "zip <s1 []any> ... <sN []any>",
+		Description: []string{
+			"Returns a slice of slices, where the i-th slice contains the i-th element of each argument.",
+			"The result is as long as the shortest argument",
+		},
+		Functions: template.FuncMap{"zip": func(s ...any) ([]any, error) {
+			if len(s) == 0 {
+				return []any{}, nil
+			}
+			slices := make([][]any, len(s))
+			length := -1
+			for i := range s {
+				v, err := toSlice(s[i])
+				if err != nil {
+					return nil, err
+				}
+				slices[i] = v
+				if length < 0 || len(v) < length {
+					length = len(v)
+				}
+			}
+			ret := make([]any, length)
+			for i := range ret {
+				tuple := make([]any, len(slices))
+				for j := range slices {
+					tuple[j] = slices[j][i]
+				}
+				ret[i] = tuple
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "first|last <s []any>",
+		Description: []string{"Returns the first/last element of s, or nil if s is empty"},
+		Functions: template.FuncMap{
+			"first": func(s any) (any, error) {
+				v, err := toSlice(s)
+				if err != nil || len(v) == 0 {
+					return nil, err
+				}
+				return v[0], nil
+			},
+			"last": func(s any) (any, error) {
+				v, err := toSlice(s)
+				if err != nil || len(v) == 0 {
+					return nil, err
+				}
+				return v[len(v)-1], nil
+			},
+		},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "rest|initial <s []any>",
+		Description: []string{"Returns all the elements of s but the first/last one"},
+		Functions: template.FuncMap{
+			"rest": func(s any) ([]any, error) {
+				v, err := toSlice(s)
+				if err != nil || len(v) == 0 {
+					return []any{}, err
+				}
+				return append([]any{}, v[1:]...), nil
+			},
+			"initial": func(s any) ([]any, error) {
+				v, err := toSlice(s)
+				if err != nil || len(v) == 0 {
+					return []any{}, err
+				}
+				return append([]any{}, v[:len(v)-1]...), nil
+			},
+		},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "subslice <v []any|string> [<start int> [<end int>]]",
+		Description: []string{
+			"Returns v[start:end]. start defaults to 0 and end to the length of v. Negative indices count from the end of v.",
+			"Strings are indexed by character, not by byte.",
+			"Out of range indices are clamped. Unlike the builtin slice function, always returns a []any for slices",
+		},
+		Functions: template.FuncMap{"subslice": func(v any, indices ...int) (any, error) {
+			if len(indices) > 2 {
+				return nil, fmt.Errorf("Too many indices: %d", len(indices))
+			}
+			if s, ok := v.(string); ok {
+				r := []rune(s)
+				start, end := sliceBounds(len(r), indices)
+				return string(r[start:end]), nil
+			}
+			s, err := toSlice(v)
+			if err != nil {
+				return nil, err
+			}
+			start, end := sliceBounds(len(s), indices)
+			return append([]any{}, s[start:end]...), nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "index_of <v any> <s []any>",
+		Description: []string{"Returns the index of the first occurrence of v in s, or -1 if s does not contain v. Numbers are compared by value"},
+		Functions: template.FuncMap{"index_of": func(e any, s any) (int, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return -1, err
+			}
+			return indexOf(e, v), nil
+		}},
+	},
+}
+
+func indexOf(e any, s []any) int {
+	for i := range s {
+		if equalValues(e, s[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func flatten(dst []any, s []any) []any {
+	for _, e := range s {
+		if _, ok := e.(Bytes); !ok && e != nil {
+			if kind := reflect.ValueOf(e).Kind(); kind == reflect.Slice || kind == reflect.Array {
+				nested, _ := toSlice(e)
+				dst = flatten(dst, nested)
+				continue
+			}
+		}
+		dst = append(dst, e)
+	}
+	return dst
+}
+
+// sliceBounds resolves the optional start and end indices for a value of the given length
+func sliceBounds(length int, indices []int) (int, int) {
+	bound := func(i int) int {
+		if i < 0 {
+			i += length
+		}
+		return max(0, min(i, length))
+	}
+
+	start, end := 0, length
+	if len(indices) > 0 {
+		start = bound(indices[0])
+	}
+	if len(indices) > 1 {
+		end = bound(indices[1])
+	}
+	return start, max(start, end)
+}
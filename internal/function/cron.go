@@ -0,0 +1,311 @@
+package function
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const cronCategory = "Cron"
+
+// cronField describes one of the 5 fields of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the allowed values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day fields are unrestricted (*), as cron matches either of them
+	// when both are restricted
+	domStar, dowStar bool
+}
+
+var systemdWeekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var cronFuncs = []FunctionSet{
+	{
+		Category: cronCategory,
+		Syntax:   "cron_validate <expr string>",
+		Description: []string{
+			"Returns expr if it is a valid cron expression, fails otherwise. Expressions have 5 fields (minute, hour, day of month,",
+			"month and day of week) supporting *, lists, ranges, steps and month and day names, or are one of the @yearly, @annually,",
+			"@monthly, @weekly, @daily, @midnight and @hourly macros",
+		},
+		Functions: template.FuncMap{"cron_validate": func(expr string) (string, error) {
+			if _, err := parseCron(expr); err != nil {
+				return "", err
+			}
+			return expr, nil
+		}},
+	},
+	{
+		Category: cronCategory,
+		Syntax:   "cron_next [<n int> [<from time.Time|string|number>]] <expr string>",
+		Description: []string{
+			"Returns the next time expr fires after from, which defaults to the current time (see the -now flag).",
+			"When n is given, returns a slice of the next n times instead. Times are in the time zone of from",
+		},
+		Functions: template.FuncMap{"cron_next": func(args ...any) (any, error) {
+			if len(args) < 1 || len(args) > 3 {
+				return nil, fmt.Errorf("Invalid number of arguments: %d", len(args))
+			}
+			expr, ok := args[len(args)-1].(string)
+			if !ok {
+				return nil, fmt.Errorf("Unsupported type: %T", args[len(args)-1])
+			}
+			schedule, err := parseCron(expr)
+			if err != nil {
+				return nil, err
+			}
+
+			from := now()
+			if len(args) == 3 {
+				if from, err = toTime(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			if len(args) == 1 {
+				return schedule.next(from)
+			}
+
+			n, ok := toFloat(args[0])
+			if !ok || n != float64(int(n)) || n < 0 {
+				return nil, fmt.Errorf("Invalid count: %v", args[0])
+			}
+			times := make([]any, 0, int(n))
+			for range int(n) {
+				if from, err = schedule.next(from); err != nil {
+					return nil, err
+				}
+				times = append(times, from)
+			}
+			return times, nil
+		}},
+	},
+	{
+		Category: cronCategory,
+		Syntax:   "cron_to_systemd <expr string>",
+		Description: []string{
+			"Converts the cron expression expr to a systemd OnCalendar expression. Fails if both the day of month and",
+			"the day of week are restricted, as cron fires when either matches whereas systemd requires both to match",
+		},
+		Functions: template.FuncMap{"cron_to_systemd": func(expr string) (string, error) {
+			schedule, err := parseCron(expr)
+			if err != nil {
+				return "", err
+			}
+			return schedule.systemd(expr)
+		}},
+	},
+}
+
+func parseCron(expr string) (*cronSchedule, error) {
+	normalized := strings.TrimSpace(expr)
+	if strings.HasPrefix(normalized, "@") {
+		var ok bool
+		if normalized, ok = cronMacros[strings.ToLower(normalized)]; !ok {
+			return nil, fmt.Errorf("Invalid cron expression %q: unknown macro", expr)
+		}
+	}
+
+	fields := strings.Fields(normalized)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("Invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := cronFields[i].parse(strings.ToLower(field))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cron expression %q: invalid %s field: %w", expr, cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+
+	// 7 is an alias for Sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return &cronSchedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parse returns the set of values matched by the field
+func (f cronField) parse(field string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step: %s", stepStr)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(loStr); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = f.value(hiStr); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("invalid range: %s", rng)
+				}
+			case !hasStep:
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses a single value of the field, either a number or a name
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && name == s {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value: %s", s)
+	}
+	return v, nil
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if !s.domStar && !s.dowStar {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// next returns the first time strictly after from matching the schedule
+func (s *cronSchedule) next(from time.Time) (time.Time, error) {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	// Every valid schedule fires at least once in 4 years (29th of February) or 28 years (weekdays)
+	limit := t.AddDate(28, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		default:
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("The cron expression never fires")
+}
+
+func (s *cronSchedule) systemd(expr string) (string, error) {
+	var sb strings.Builder
+
+	if !s.dowStar {
+		if !s.domStar {
+			return "", fmt.Errorf("Cannot convert cron expression %q to systemd: both the day of month and the day of week are restricted", expr)
+		}
+		// systemd weeks start on Monday, so Sunday is moved to 7
+		dow := s.dow&^1 | (s.dow&1)<<7
+		sb.WriteString(compressSet(dow, 1, 7, false, func(v int) string { return systemdWeekdays[v%7] }))
+		sb.WriteByte(' ')
+	}
+
+	pad := func(v int) string { return fmt.Sprintf("%02d", v) }
+	fmt.Fprintf(&sb, "*-%s-%s %s:%s:00",
+		compressSet(s.month, 1, 12, true, pad),
+		compressSet(s.dom, 1, 31, true, pad),
+		compressSet(s.hour, 0, 23, true, pad),
+		compressSet(s.minute, 0, 59, true, pad))
+	return sb.String(), nil
+}
+
+// compressSet formats the values of set within [min, max] as *, start/step (if steps is set), or a list of values
+// and ranges (a..b)
+func compressSet(set uint64, min, max int, steps bool, format func(int) string) string {
+	var values []int
+	for v := min; v <= max; v++ {
+		if set&(1<<v) != 0 {
+			values = append(values, v)
+		}
+	}
+	if len(values) == max-min+1 {
+		return "*"
+	}
+
+	if steps && len(values) > 2 {
+		step := values[1] - values[0]
+		regular := values[len(values)-1]+step > max
+		for i := 2; regular && i < len(values); i++ {
+			regular = values[i]-values[i-1] == step
+		}
+		if regular && step > 1 {
+			return format(values[0]) + "/" + strconv.Itoa(step)
+		}
+	}
+
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, format(values[i])+".."+format(values[j]))
+		case j > i:
+			parts = append(parts, format(values[i]), format(values[j]))
+		default:
+			parts = append(parts, format(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
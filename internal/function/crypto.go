@@ -0,0 +1,125 @@
+package function
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"text/template"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const cryptoCategory = "Crypto"
+
+var cryptoFuncs = []FunctionSet{
+	{
+		Category: cryptoCategory,
+		Syntax:   "md5|sha1|sha256|sha512|blake2b[_base64|_bytes] <val string|Bytes>",
+		Description: []string{
+			"Returns the hash of val in hexadecimal. blake2b is BLAKE2b-512.",
+			"_base64 variants return the hash encoded in Base64, and _bytes variants return the raw hash as Bytes",
+		},
+		Functions: hashFuncs(map[string]func() hash.Hash{
+			"md5":     md5.New,
+			"sha1":    sha1.New,
+			"sha256":  sha256.New,
+			"sha512":  sha512.New,
+			"blake2b": newBlake2b,
+		}),
+	},
+	{
+		Category: cryptoCategory,
+		Syntax:   "hmac_sha256|hmac_sha512[_base64|_bytes] <key string|Bytes> <val string|Bytes>",
+		Description: []string{
+			"Returns the HMAC of val using key in hexadecimal.",
+			"_base64 variants return the HMAC encoded in Base64, and _bytes variants return the raw HMAC as Bytes",
+		},
+		Functions: hmacFuncs(map[string]func() hash.Hash{
+			"hmac_sha256": sha256.New,
+			"hmac_sha512": sha512.New,
+		}),
+	},
+	{
+		Category:    cryptoCategory,
+		Syntax:      "crc32|adler32 <val string|Bytes>",
+		Description: []string{"Returns the CRC-32 (IEEE polynomial)/Adler-32 checksum of val as an int"},
+		Functions: template.FuncMap{
+			"crc32": func(v any) (int, error) {
+				data, err := toBytes(v)
+				return int(crc32.ChecksumIEEE(data)), err
+			},
+			"adler32": func(v any) (int, error) {
+				data, err := toBytes(v)
+				return int(adler32.Checksum(data)), err
+			},
+		},
+	},
+}
+
+func newBlake2b() hash.Hash {
+	h, _ := blake2b.New512(nil)
+	return h
+}
+
+// digestFormats returns the functions formatting a digest for each function suffix
+var digestFormats = map[string]func([]byte) any{
+	"": func(d []byte) any {
+		return hex.EncodeToString(d)
+	},
+	"_base64": func(d []byte) any {
+		return base64.StdEncoding.EncodeToString(d)
+	},
+	"_bytes": func(d []byte) any {
+		return Bytes(d)
+	},
+}
+
+// hashFuncs returns the template functions computing the hashes of the given algorithms, in all the output formats
+func hashFuncs(algorithms map[string]func() hash.Hash) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, newHash := range algorithms {
+		for suffix, format := range digestFormats {
+			funcs[name+suffix] = func(v any) (any, error) {
+				data, err := toBytes(v)
+				if err != nil {
+					return nil, err
+				}
+				h := newHash()
+				h.Write(data)
+				return format(h.Sum(nil)), nil
+			}
+		}
+	}
+	return funcs
+}
+
+// hmacFuncs returns the template functions computing the HMACs based on the given algorithms, in all the output
+// formats
+func hmacFuncs(algorithms map[string]func() hash.Hash) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, newHash := range algorithms {
+		for suffix, format := range digestFormats {
+			funcs[name+suffix] = func(key, v any) (any, error) {
+				k, err := toBytes(key)
+				if err != nil {
+					return nil, err
+				}
+				data, err := toBytes(v)
+				if err != nil {
+					return nil, err
+				}
+				h := hmac.New(newHash, k)
+				h.Write(data)
+				return format(h.Sum(nil)), nil
+			}
+		}
+	}
+	return funcs
+}
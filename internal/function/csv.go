@@ -0,0 +1,296 @@
+package function
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+const csvCategory = "CSV"
+
+var csvFuncs = []FunctionSet{
+	{
+		Category: csvCategory,
+		Syntax:   "from_csv|from_tsv [<options map[string]any>] <s string>",
+		Description: []string{
+			"Decodes the CSV/TSV string s. Returns a slice of records, each record being a slice of strings.",
+			"The following options are supported:",
+			"  header (bool): use the first record as keys, and return a slice of maps instead",
+			"  separator (string): the field separator, defaults to , for CSV and tab for TSV",
+			"  comment (string): lines starting with this character are ignored",
+			"  lazy_quotes (bool): allow quotes in unquoted fields and non-doubled quotes in quoted fields",
+			"  trim_leading_space (bool): ignore leading white space in fields",
+		},
+		Functions: template.FuncMap{
+			"from_csv": func(args ...any) ([]any, error) {
+				return decodeCSVArgs(args, ',', func(s string) ([]byte, error) { return []byte(s), nil })
+			},
+			"from_tsv": func(args ...any) ([]any, error) {
+				return decodeCSVArgs(args, '\t', func(s string) ([]byte, error) { return []byte(s), nil })
+			},
+		},
+	},
+	{
+		Category: csvCategory,
+		Syntax:   "read_csv|read_tsv [<options map[string]any>] <filename string>",
+		Description: []string{
+			"Reads the given CSV/TSV file, relative to the current directory, and decodes it like from_csv/from_tsv does",
+		},
+		Functions: template.FuncMap{
+			"read_csv": func(args ...any) ([]any, error) {
+				return decodeCSVArgs(args, ',', os.ReadFile)
+			},
+			"read_tsv": func(args ...any) ([]any, error) {
+				return decodeCSVArgs(args, '\t', os.ReadFile)
+			},
+		},
+	},
+	{
+		Category: csvCategory,
+		Syntax:   "to_csv|to_tsv [<options map[string]any>] <records []any>",
+		Description: []string{
+			"Encodes records to CSV/TSV. Records are either slices of values, or maps which are written as a header row",
+			"followed by one row per map. The following options are supported:",
+			"  columns ([]string): the keys of the maps to write, in order. Defaults to all the keys, sorted",
+			"  header (bool): write the header row for maps, defaults to true",
+			"  separator (string): the field separator, defaults to , for CSV and tab for TSV",
+			"  quote_all (bool): quote all the fields, not only the ones which require it",
+			"  crlf (bool): end lines with \\r\\n instead of \\n",
+		},
+		Functions: template.FuncMap{
+			"to_csv": func(args ...any) (string, error) {
+				return encodeCSVArgs(args, ',')
+			},
+			"to_tsv": func(args ...any) (string, error) {
+				return encodeCSVArgs(args, '\t')
+			},
+		},
+	},
+}
+
+type csvOptions struct {
+	header           bool
+	separator        rune
+	comment          rune
+	lazyQuotes       bool
+	trimLeadingSpace bool
+	columns          []string
+	quoteAll         bool
+	crlf             bool
+}
+
+func parseCSVOptions(m map[string]any, separator rune, header bool) (*csvOptions, error) {
+	options := &csvOptions{separator: separator, header: header}
+
+	for k, v := range m {
+		var ok bool
+		switch k {
+		case "header":
+			options.header, ok = v.(bool)
+		case "lazy_quotes":
+			options.lazyQuotes, ok = v.(bool)
+		case "trim_leading_space":
+			options.trimLeadingSpace, ok = v.(bool)
+		case "quote_all":
+			options.quoteAll, ok = v.(bool)
+		case "crlf":
+			options.crlf, ok = v.(bool)
+		case "separator", "comment":
+			var s string
+			if s, ok = v.(string); ok && utf8.RuneCountInString(s) == 1 {
+				r, _ := utf8.DecodeRuneInString(s)
+				if k == "separator" {
+					options.separator = r
+				} else {
+					options.comment = r
+				}
+			} else {
+				ok = false
+			}
+		case "columns":
+			columns, err := toSlice(v)
+			ok = err == nil
+			for _, c := range columns {
+				options.columns = append(options.columns, fmt.Sprint(c))
+			}
+		default:
+			return nil, fmt.Errorf("Unknown option: %s", k)
+		}
+		if !ok {
+			return nil, fmt.Errorf("Invalid value for option %s: %v", k, v)
+		}
+	}
+	return options, nil
+}
+
+func decodeCSVArgs(args []any, separator rune, load func(string) ([]byte, error)) ([]any, error) {
+	m, v, err := optionArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	options, err := parseCSVOptions(m, separator, false)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported type: %T", v)
+	}
+	data, err := load(s)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCSV(data, options)
+}
+
+func decodeCSV(data []byte, options *csvOptions) ([]any, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = options.separator
+	r.Comment = options.comment
+	r.LazyQuotes = options.lazyQuotes
+	r.TrimLeadingSpace = options.trimLeadingSpace
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.header {
+		ret := make([]any, len(records))
+		for i, record := range records {
+			row := make([]any, len(record))
+			for j := range record {
+				row[j] = record[j]
+			}
+			ret[i] = row
+		}
+		return ret, nil
+	}
+
+	if len(records) == 0 {
+		return []any{}, nil
+	}
+	header := records[0]
+	ret := make([]any, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if len(record) > len(header) {
+			return nil, fmt.Errorf("Record %d has %d fields, more than the %d columns of the header", i+2, len(record), len(header))
+		}
+		row := make(map[string]any, len(header))
+		for j, k := range header {
+			if j < len(record) {
+				row[k] = record[j]
+			} else {
+				row[k] = ""
+			}
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func encodeCSVArgs(args []any, separator rune) (string, error) {
+	m, v, err := optionArgs(args)
+	if err != nil {
+		return "", err
+	}
+	options, err := parseCSVOptions(m, separator, true)
+	if err != nil {
+		return "", err
+	}
+	records, err := toSlice(v)
+	if err != nil {
+		return "", err
+	}
+
+	rows := make([][]string, 0, len(records)+1)
+	if len(records) > 0 && isMap(records[0]) {
+		columns := options.columns
+		if columns == nil {
+			all := map[string]any{}
+			for _, record := range records {
+				keys, err := sortedKeys(record)
+				if err != nil {
+					return "", err
+				}
+				for _, k := range keys {
+					all[k] = nil
+				}
+			}
+			columns, _ = sortedKeys(all)
+		}
+		if options.header {
+			rows = append(rows, columns)
+		}
+		for _, record := range records {
+			mm, err := toMap(record)
+			if err != nil {
+				return "", err
+			}
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				row[i] = csvField(mm[c])
+			}
+			rows = append(rows, row)
+		}
+	} else {
+		for _, record := range records {
+			fields, err := toSlice(record)
+			if err != nil {
+				return "", err
+			}
+			row := make([]string, len(fields))
+			for i := range fields {
+				row[i] = csvField(fields[i])
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if options.quoteAll {
+		return writeQuotedCSV(rows, options), nil
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = options.separator
+	w.UseCRLF = options.crlf
+	if err = w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeQuotedCSV writes rows with all the fields quoted, which encoding/csv does not support
+func writeQuotedCSV(rows [][]string, options *csvOptions) string {
+	eol := "\n"
+	if options.crlf {
+		eol = "\r\n"
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		for i, field := range row {
+			if i > 0 {
+				b.WriteRune(options.separator)
+			}
+			field = strings.ReplaceAll(field, `"`, `""`)
+			if options.crlf {
+				field = strings.ReplaceAll(field, "\n", "\r\n")
+			}
+			b.WriteString(`"` + field + `"`)
+		}
+		b.WriteString(eol)
+	}
+	return b.String()
+}
+
+func csvField(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,101 @@
+package function
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// baseDir is the directory relative data file paths are resolved against. The current directory is used if empty
+var baseDir string
+
+// dataCache contains the data files already loaded, by format and absolute path
+var dataCache = map[string]any{}
+
+// dataDecoders contains the decoders of the supported data formats
+var dataDecoders = map[string]func([]byte) (any, error){
+	"json": unmarshalJSON,
+	"yaml": unmarshalYAML,
+	"toml": func(data []byte) (any, error) {
+		return unmarshalTOML(data)
+	},
+}
+
+// dataExtensions maps file extensions to data formats
+var dataExtensions = map[string]string{
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+}
+
+// SetBaseDir sets the directory relative paths given to the read_json, read_yaml, read_toml and read_data functions
+// are resolved against
+func SetBaseDir(dir string) {
+	baseDir = dir
+}
+
+var dataFuncs = []FunctionSet{
+	{
+		Category: ioCategory,
+		Syntax:   "read_json|read_yaml|read_toml <filename string>",
+		Description: []string{
+			"Reads and decodes the given JSON/YAML/TOML file. Relative filenames are resolved against the directory of the template",
+			"(or the current directory for inline and stdin templates). Files are only read once per run",
+		},
+		Functions: template.FuncMap{
+			"read_json": func(filename string) (any, error) {
+				return readData("json", filename)
+			},
+			"read_yaml": func(filename string) (any, error) {
+				return readData("yaml", filename)
+			},
+			"read_toml": func(filename string) (any, error) {
+				return readData("toml", filename)
+			},
+		},
+	},
+	{
+		Category: ioCategory,
+		Syntax:   "read_data <filename string>",
+		Description: []string{
+			"Reads and decodes the given file as read_json, read_yaml or read_toml do, depending on its extension",
+			"(.json, .yaml, .yml or .toml). Relative filenames are resolved against the directory of the template as well",
+		},
+		Functions: template.FuncMap{"read_data": func(filename string) (any, error) {
+			format, ok := dataExtensions[strings.ToLower(filepath.Ext(filename))]
+			if !ok {
+				return nil, fmt.Errorf("Unknown data format: %s", filename)
+			}
+			return readData(format, filename)
+		}},
+	},
+}
+
+// readData loads filename using the given format. The result is cached, and a copy is returned so that
+// templates modifying it (e.g. using set) don't affect later reads
+func readData(format string, filename string) (any, error) {
+	if !filepath.IsAbs(filename) && baseDir != "" {
+		filename = filepath.Join(baseDir, filename)
+	}
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	key := format + ":" + path
+	v, ok := dataCache[key]
+	if !ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = dataDecoders[format](data); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		dataCache[key] = v
+	}
+	return deepCopy(v), nil
+}
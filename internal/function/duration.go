@@ -0,0 +1,213 @@
+package function
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	durationRegexp          = regexp.MustCompile(`^[-+]?((\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h|d|w))+$`)
+	durationComponentRegexp = regexp.MustCompile(`(\d+(?:\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h|d|w)`)
+	isoDurationRegexp       = regexp.MustCompile(`^([-+])?P(?:([\d.]+)W)?(?:([\d.]+)D)?(?:T(?:([\d.]+)H)?(?:([\d.]+)M)?(?:([\d.]+)S)?)?$`)
+)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// humanUnits are the units used by humanize_duration, from the largest to the smallest
+var humanUnits = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+	{"millisecond", time.Millisecond},
+}
+
+var durationFuncs = []FunctionSet{
+	{
+		Category: timeCategory,
+		Syntax:   "parse_duration <d string|number>",
+		Description: []string{
+			"Parses d, which is either a Go duration (1h30m, 90s), a duration with days and weeks (1w2d12h), an ISO 8601 duration",
+			"(PT1H30M, P1DT12H) or a number of seconds. Years and months are not supported, as their duration varies",
+		},
+		Functions: template.FuncMap{"parse_duration": toDuration},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "to_seconds|to_millis <d time.Duration|string|number>",
+		Description: []string{
+			"Returns d, parsed as parse_duration does, as a number of seconds/milliseconds.",
+			"Seconds may be fractional, milliseconds are truncated",
+		},
+		Functions: template.FuncMap{
+			"to_seconds": func(v any) (float64, error) {
+				d, err := toDuration(v)
+				return d.Seconds(), err
+			},
+			"to_millis": func(v any) (int64, error) {
+				d, err := toDuration(v)
+				return d.Milliseconds(), err
+			},
+		},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "humanize_duration [<parts int>] <d time.Duration|string|number>",
+		Description: []string{
+			"Returns d in words, e.g. \"1 hour 30 minutes\". When parts is given, only the parts largest units are used (\"1 hour\")",
+		},
+		Functions: template.FuncMap{"humanize_duration": func(args ...any) (string, error) {
+			parts, v, err := partsArgs(args, 0)
+			if err != nil {
+				return "", err
+			}
+			d, err := toDuration(v)
+			if err != nil {
+				return "", err
+			}
+			return humanizeDuration(d, parts), nil
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "ago [<parts int>] <t time.Time|string|number>",
+		Description: []string{
+			"Returns the time elapsed since t in words, using the parts largest units (defaults to 1), e.g. \"3 hours ago\".",
+			"Times in the future give e.g. \"in 3 hours\"",
+		},
+		Functions: template.FuncMap{"ago": func(args ...any) (string, error) {
+			parts, v, err := partsArgs(args, 1)
+			if err != nil {
+				return "", err
+			}
+			t, err := toTime(v)
+			if err != nil {
+				return "", err
+			}
+			d := now().Sub(t)
+			if d < 0 {
+				return "in " + humanizeDuration(-d, parts), nil
+			}
+			return humanizeDuration(d, parts) + " ago", nil
+		}},
+	},
+}
+
+// partsArgs extracts the optional number of parts and the value from the arguments of a function with the
+// [<parts int>] <v any> syntax
+func partsArgs(args []any, def int) (int, any, error) {
+	switch len(args) {
+	case 1:
+		return def, args[0], nil
+	case 2:
+		parts, ok := toFloat(args[0])
+		if !ok || parts != float64(int(parts)) || parts < 1 {
+			return 0, nil, fmt.Errorf("Invalid number of parts: %v", args[0])
+		}
+		return int(parts), args[1], nil
+	default:
+		return 0, nil, fmt.Errorf("Invalid number of arguments: %d", len(args))
+	}
+}
+
+// toDuration converts v to a duration. Strings are parsed by parseDuration, and numbers are seconds
+func toDuration(v any) (time.Duration, error) {
+	switch v := v.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return parseDuration(v)
+	}
+
+	f, ok := toFloat(v)
+	if !ok {
+		return 0, fmt.Errorf("Unsupported type: %T", v)
+	}
+	return time.Duration(math.Round(f * float64(time.Second))), nil
+}
+
+// parseDuration parses Go durations extended with days and weeks, ISO 8601 durations and numbers of seconds
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(math.Round(f * float64(time.Second))), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	var total float64
+	negative := strings.HasPrefix(s, "-")
+	if durationRegexp.MatchString(s) {
+		for _, match := range durationComponentRegexp.FindAllStringSubmatch(s, -1) {
+			f, _ := strconv.ParseFloat(match[1], 64)
+			total += f * float64(durationUnits[match[2]])
+		}
+	} else if match := isoDurationRegexp.FindStringSubmatch(strings.ToUpper(s)); match != nil && !strings.HasSuffix(match[0], "P") && !strings.HasSuffix(match[0], "T") {
+		for i, unit := range []string{"w", "d", "h", "m", "s"} {
+			if match[i+2] == "" {
+				continue
+			}
+			f, err := strconv.ParseFloat(match[i+2], 64)
+			if err != nil {
+				return 0, fmt.Errorf("Invalid duration: %s", s)
+			}
+			total += f * float64(durationUnits[unit])
+		}
+	} else {
+		return 0, fmt.Errorf("Invalid duration: %s", s)
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("Invalid duration: %s", s)
+	}
+	if negative {
+		total = -total
+	}
+	return time.Duration(math.Round(total)), nil
+}
+
+// humanizeDuration returns d in words, using at most parts units (all of them if parts is 0)
+func humanizeDuration(d time.Duration, parts int) string {
+	var words []string
+	if d < 0 {
+		words = append(words, "minus")
+		d = -d
+	}
+
+	n := 0
+	for _, unit := range humanUnits {
+		if d < unit.duration || (parts > 0 && n == parts) {
+			continue
+		}
+		count := d / unit.duration
+		d -= count * unit.duration
+		n++
+		words = append(words, strconv.FormatInt(int64(count), 10), unit.name)
+		if count > 1 {
+			words[len(words)-1] += "s"
+		}
+	}
+	if n == 0 {
+		words = append(words, "0 seconds")
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,339 @@
+package function
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const encodingCategory = "Encoding"
+
+var encodingFuncs = []FunctionSet{
+	{
+		Category: encodingCategory,
+		Syntax:   "to_yaml [<indent int>] <v any>",
+		Description: []string{
+			"Encodes v to YAML, indenting nested blocks with indent spaces, between 2 and 9 (defaults to 2). Map keys are sorted.",
+			"The trailing newline is removed. Use the indent and nindent functions to place the result at a given nesting level",
+		},
+		Functions: template.FuncMap{"to_yaml": func(args ...any) (string, error) {
+			indent, v, err := indentWidthArgs(args)
+			if err != nil {
+				return "", err
+			}
+			// The YAML encoder silently replaces other values
+			if indent < 2 || indent > 9 {
+				return "", fmt.Errorf("Invalid indentation: %d, must be between 2 and 9", indent)
+			}
+			var buf bytes.Buffer
+			enc := yaml.NewEncoder(&buf)
+			enc.SetIndent(indent)
+			if err = enc.Encode(v); err != nil {
+				return "", err
+			}
+			if err = enc.Close(); err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		}},
+	},
+	{
+		Category:    encodingCategory,
+		Syntax:      "from_yaml <s string>",
+		Description: []string{"Decodes the YAML string s and returns the resulting value. Map keys are converted to strings"},
+		Functions: template.FuncMap{"from_yaml": func(s string) (any, error) {
+			return unmarshalYAML([]byte(s))
+		}},
+	},
+	{
+		Category: encodingCategory,
+		Syntax:   "to_toml [<indent int>] <v map[string]any>",
+		Description: []string{
+			"Encodes the map v to TOML, indenting nested tables with indent spaces (defaults to 2). Map keys are sorted.",
+			"Floating point numbers without a fractional part (such as numbers decoded from JSON) are encoded as integers",
+		},
+		Functions: template.FuncMap{"to_toml": func(args ...any) (string, error) {
+			indent, v, err := indentWidthArgs(args)
+			if err != nil {
+				return "", err
+			}
+			if !isMap(v) {
+				return "", fmt.Errorf("Unsupported type: %T", v)
+			}
+			var buf bytes.Buffer
+			enc := toml.NewEncoder(&buf)
+			enc.Indent = strings.Repeat(" ", indent)
+			if err = enc.Encode(integralFloatsToInts(v)); err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		}},
+	},
+	{
+		Category:    encodingCategory,
+		Syntax:      "from_toml <s string>",
+		Description: []string{"Decodes the TOML string s and returns the resulting map"},
+		Functions: template.FuncMap{"from_toml": func(s string) (map[string]any, error) {
+			return unmarshalTOML([]byte(s))
+		}},
+	},
+	{
+		Category: encodingCategory,
+		Syntax:   "to_xml [<indent int>] <v map[string]any>",
+		Description: []string{
+			"Encodes v to XML, indenting nested elements with indent spaces (defaults to 2). v must be a map with a single key, the root element.",
+			"Maps are encoded as child elements in the order of the sorted keys, except @-prefixed keys which are encoded as attributes",
+			"and the #text key which is encoded as the text content. Slices are encoded as repeated elements",
+		},
+		Functions: template.FuncMap{"to_xml": func(args ...any) (string, error) {
+			indent, v, err := indentWidthArgs(args)
+			if err != nil {
+				return "", err
+			}
+			m, err := toMap(v)
+			if err != nil {
+				return "", err
+			}
+			if len(m) != 1 {
+				return "", fmt.Errorf("XML documents must have exactly one root element, got %d", len(m))
+			}
+
+			var buf bytes.Buffer
+			enc := xml.NewEncoder(&buf)
+			enc.Indent("", strings.Repeat(" ", indent))
+			for k, v := range m {
+				if err = encodeXMLElement(enc, k, v); err != nil {
+					return "", err
+				}
+			}
+			if err = enc.Flush(); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}},
+	},
+	{
+		Category: encodingCategory,
+		Syntax:   "from_xml <s string>",
+		Description: []string{
+			"Decodes the XML string s into a map with the root element as the single key, following the conventions of to_xml.",
+			"Elements containing only text are decoded as strings, repeated elements as slices",
+		},
+		Functions: template.FuncMap{"from_xml": func(s string) (map[string]any, error) {
+			return unmarshalXML([]byte(s))
+		}},
+	},
+}
+
+// indentWidthArgs extracts the optional indentation width and the value from the arguments of a function with the
+// [<indent int>] <v any> syntax
+func indentWidthArgs(args []any) (int, any, error) {
+	switch len(args) {
+	case 1:
+		return 2, args[0], nil
+	case 2:
+		indent, ok := args[0].(int)
+		if !ok || indent < 0 {
+			return 0, nil, fmt.Errorf("Invalid indentation: %v", args[0])
+		}
+		return indent, args[1], nil
+	default:
+		return 0, nil, fmt.Errorf("Invalid number of arguments: %d", len(args))
+	}
+}
+
+func unmarshalYAML(data []byte) (any, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return stringifyKeys(v), nil
+}
+
+func unmarshalTOML(data []byte) (map[string]any, error) {
+	m := map[string]any{}
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// stringifyKeys recursively converts the map[any]any values produced by the YAML decoder to map[string]any
+func stringifyKeys(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringifyKeys(e)
+		}
+		return m
+	case map[string]any:
+		for k, e := range v {
+			v[k] = stringifyKeys(e)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = stringifyKeys(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// integralFloatsToInts returns a copy of v where floating point numbers without a fractional part are converted to
+// int64, recursively
+func integralFloatsToInts(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return int64(v)
+		}
+		return v
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = integralFloatsToInts(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i := range v {
+			s[i] = integralFloatsToInts(v[i])
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func encodeXMLElement(enc *xml.Encoder, name string, v any) error {
+	if v != nil && !isMap(v) {
+		if s, err := toSlice(v); err == nil {
+			for _, e := range s {
+				if err = encodeXMLElement(enc, name, e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	var children map[string]any
+	if isMap(v) {
+		m, err := toMap(v)
+		if err != nil {
+			return err
+		}
+		keys, _ := sortedKeys(m)
+		children = make(map[string]any, len(m))
+		for _, k := range keys {
+			if attr, ok := strings.CutPrefix(k, "@"); ok {
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr}, Value: fmt.Sprint(m[k])})
+			} else {
+				children[k] = m[k]
+			}
+		}
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if children == nil {
+		if v != nil {
+			if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+				return err
+			}
+		}
+	} else {
+		if text, ok := children["#text"]; ok {
+			if err := enc.EncodeToken(xml.CharData(fmt.Sprint(text))); err != nil {
+				return err
+			}
+		}
+		keys, _ := sortedKeys(children)
+		for _, k := range keys {
+			if k == "#text" {
+				continue
+			}
+			if err := encodeXMLElement(enc, k, children[k]); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func unmarshalXML(data []byte) (map[string]any, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("No root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := decodeXMLElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{start.Name.Local: v}, nil
+		}
+	}
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	m := map[string]any{}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		m["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, tok)
+			if err != nil {
+				return nil, err
+			}
+			name := tok.Name.Local
+			switch existing := m[name].(type) {
+			case nil:
+				m[name] = child
+			case []any:
+				m[name] = append(existing, child)
+			default:
+				m[name] = []any{existing, child}
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				m["#text"] = s
+			}
+			return m, nil
+		}
+	}
+}
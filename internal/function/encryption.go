@@ -0,0 +1,198 @@
+package function
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const encryptionCategory = "Encryption"
+
+var encryptionFuncs = []FunctionSet{
+	{
+		Category: encryptionCategory,
+		Syntax:   "aes_encrypt <key_ref string> <plaintext string|Bytes>",
+		Description: []string{
+			"Encrypts plaintext with AES-GCM, and returns the Base64 encoding of the random nonce followed by the ciphertext.",
+			"key_ref is either env:NAME or file:PATH (relative to the current directory), and designates the Base64 encoded",
+			"16, 24 or 32 bytes key. Keys cannot be given inline, and rendering fails if the key is missing",
+		},
+		Functions: template.FuncMap{"aes_encrypt": func(keyRef string, plaintext any) (string, error) {
+			data, err := toBytes(plaintext)
+			if err != nil {
+				return "", err
+			}
+			gcm, err := aesGCM(keyRef)
+			if err != nil {
+				return "", err
+			}
+			nonce := make([]byte, gcm.NonceSize())
+			if _, err = rand.Read(nonce); err != nil {
+				return "", err
+			}
+			return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, data, nil)), nil
+		}},
+	},
+	{
+		Category: encryptionCategory,
+		Syntax:   "aes_decrypt[_bytes] <key_ref string> <ciphertext string>",
+		Description: []string{
+			"Decrypts ciphertext, as produced by aes_encrypt, with AES-GCM. See aes_encrypt for key_ref.",
+			"The _bytes variant returns Bytes instead of a string",
+		},
+		Functions: template.FuncMap{
+			"aes_decrypt": func(keyRef string, ciphertext string) (string, error) {
+				data, err := aesDecrypt(keyRef, ciphertext)
+				return string(data), err
+			},
+			"aes_decrypt_bytes": aesDecrypt,
+		},
+	},
+	{
+		Category: encryptionCategory,
+		Syntax:   "age_encrypt <recipients string> <plaintext string|Bytes>",
+		Description: []string{
+			"Encrypts plaintext with age, and returns the ASCII armored result. recipients is either a public key (age1...),",
+			"or env:NAME or file:PATH (relative to the current directory) designating a list of public keys, one per line",
+		},
+		Functions: template.FuncMap{"age_encrypt": func(recipientsRef string, plaintext any) (string, error) {
+			data, err := toBytes(plaintext)
+			if err != nil {
+				return "", err
+			}
+			text := recipientsRef
+			if strings.HasPrefix(recipientsRef, "env:") || strings.HasPrefix(recipientsRef, "file:") {
+				key, err := loadKey(recipientsRef)
+				if err != nil {
+					return "", err
+				}
+				text = string(key)
+			}
+			recipients, err := age.ParseRecipients(strings.NewReader(text))
+			if err != nil {
+				return "", err
+			}
+
+			var buf bytes.Buffer
+			armored := armor.NewWriter(&buf)
+			w, err := age.Encrypt(armored, recipients...)
+			if err != nil {
+				return "", err
+			}
+			if _, err = w.Write(data); err != nil {
+				return "", err
+			}
+			if err = w.Close(); err != nil {
+				return "", err
+			}
+			if err = armored.Close(); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}},
+	},
+	{
+		Category: encryptionCategory,
+		Syntax:   "age_decrypt[_bytes] <identities_ref string> <ciphertext string|Bytes>",
+		Description: []string{
+			"Decrypts ciphertext with age. ciphertext is either ASCII armored or binary.",
+			"identities_ref is either env:NAME or file:PATH (relative to the current directory), and designates an age identity file",
+			"(AGE-SECRET-KEY-1... lines).",
+			"The _bytes variant returns Bytes instead of a string",
+		},
+		Functions: template.FuncMap{
+			"age_decrypt": func(identitiesRef string, ciphertext any) (string, error) {
+				data, err := ageDecrypt(identitiesRef, ciphertext)
+				return string(data), err
+			},
+			"age_decrypt_bytes": ageDecrypt,
+		},
+	},
+}
+
+// loadKey returns the key designated by ref, which is either env:NAME or file:PATH
+func loadKey(ref string) ([]byte, error) {
+	kind, name, _ := strings.Cut(ref, ":")
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("Missing key: environment variable %s is not set", name)
+		}
+		return []byte(value), nil
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("Missing key: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("Invalid key reference: keys must be given as env:NAME or file:PATH")
+	}
+}
+
+func aesGCM(keyRef string) (cipher.AEAD, error) {
+	encoded, err := loadKey(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid AES key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func aesDecrypt(keyRef string, ciphertext string) (Bytes, error) {
+	gcm, err := aesGCM(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("Invalid ciphertext: too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func ageDecrypt(identitiesRef string, ciphertext any) (Bytes, error) {
+	data, err := toBytes(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	key, err := loadKey(identitiesRef)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := age.ParseIdentities(bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -2,7 +2,10 @@ package function
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"text/template"
 )
 
@@ -17,7 +20,10 @@ var filterFuncs = []FunctionSet{
 		Description: []string{"Use with filter or first_match. Returns a FilterFunc which applies filters to one value of the map"},
 		Functions: template.FuncMap{"filter_map_value": func(k string, filters ...FilterFunc) FilterFunc {
 			return func(v any) bool {
-				m := v.(map[string]any)
+				m, ok := v.(map[string]any)
+				if !ok {
+					return false
+				}
 				return filterAnd(m[k], filters)
 			}
 		}},
@@ -26,23 +32,176 @@ var filterFuncs = []FunctionSet{
 		Category:    filterCategory,
 		Syntax:      "filter_slice_value <index int> <filter1 FilterFunc> ... <filterN FilterFunc>",
 		Description: []string{"Use with filter or first_match. Returns a FilterFunc which applies filters to one value of the slice"},
-		Functions: template.FuncMap{"fliter_slice_value": func(i int, filters ...FilterFunc) FilterFunc {
+		Functions: template.FuncMap{"filter_slice_value": func(i int, filters ...FilterFunc) FilterFunc {
 			return func(v any) bool {
-				s := v.([]any)
+				s, ok := v.([]any)
+				if !ok || i < 0 || i >= len(s) {
+					return false
+				}
 				return filterAnd(s[i], filters)
 			}
 		}},
 	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_path <path string> <filter1 FilterFunc> ... <filterN FilterFunc>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which applies filters to the value found at path in nested maps and slices.",
+			"The path is made of dot-separated keys and [index] parts (e.g. spec.ports[0].name), keys may be quoted (labels[\"app.kubernetes.io/name\"]).",
+			"Negative indices count from the end. Values where the path does not exist never match",
+		},
+		Functions: template.FuncMap{"filter_path": func(path string, filters ...FilterFunc) (FilterFunc, error) {
+			elements, err := parsePath(path)
+			if err != nil {
+				return nil, err
+			}
+			return func(v any) bool {
+				value, ok := lookupPath(v, elements)
+				return ok && filterAnd(value, filters)
+			}, nil
+		}},
+	},
+	{
+		Category:    filterCategory,
+		Syntax:      "filter_has_key <key string>",
+		Description: []string{"Use with filter or first_match. Returns a FilterFunc which checks whether the value is a map containing key"},
+		Functions: template.FuncMap{"filter_has_key": func(k string) FilterFunc {
+			elements := []pathElement{{key: k}}
+			return func(v any) bool {
+				_, ok := lookupPath(v, elements)
+				return ok
+			}
+		}},
+	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_type <type string>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks the type of the value.",
+			"type is one of string, number, bool, bytes, list, map or null",
+		},
+		Functions: template.FuncMap{"filter_type": func(t string) (FilterFunc, error) {
+			switch t {
+			case "string", "number", "bool", "bytes", "list", "map", "null":
+			default:
+				return nil, fmt.Errorf("Unknown type: %s", t)
+			}
+			return func(v any) bool {
+				return typeName(v) == t
+			}, nil
+		}},
+	},
 	{
 		Category:    filterCategory,
 		Syntax:      "filter_eq <v any>",
-		Description: []string{"Use with filter or first_match. Returns a FilterFunc which checks whether the value equals v"},
+		Description: []string{"Use with filter or first_match. Returns a FilterFunc which checks whether the value equals v. Numbers are compared by value"},
 		Functions: template.FuncMap{"filter_eq": func(v1 any) FilterFunc {
 			return func(v2 any) bool {
-				return v1 == v2
+				return equalValues(v1, v2)
 			}
 		}},
 	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_eq_i <s string>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks whether the value is a string equal to s, ignoring case",
+		},
+		Functions: template.FuncMap{"filter_eq_i": func(s string) FilterFunc {
+			return stringFilter(func(v string) bool {
+				return strings.EqualFold(v, s)
+			})
+		}},
+	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_contains[_i] <substr string>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks whether the value is a string containing substr.",
+			"The _i variant ignores case",
+		},
+		Functions: template.FuncMap{
+			"filter_contains": func(substr string) FilterFunc {
+				return stringFilter(func(v string) bool {
+					return strings.Contains(v, substr)
+				})
+			},
+			"filter_contains_i": func(substr string) FilterFunc {
+				substr = strings.ToLower(substr)
+				return stringFilter(func(v string) bool {
+					return strings.Contains(strings.ToLower(v), substr)
+				})
+			},
+		},
+	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_has_prefix[_i] <prefix string>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks whether the value is a string starting with prefix.",
+			"The _i variant ignores case",
+		},
+		Functions: template.FuncMap{
+			"filter_has_prefix": func(prefix string) FilterFunc {
+				return stringFilter(func(v string) bool {
+					return strings.HasPrefix(v, prefix)
+				})
+			},
+			"filter_has_prefix_i": func(prefix string) FilterFunc {
+				prefix = strings.ToLower(prefix)
+				return stringFilter(func(v string) bool {
+					return strings.HasPrefix(strings.ToLower(v), prefix)
+				})
+			},
+		},
+	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_has_suffix[_i] <suffix string>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks whether the value is a string ending with suffix.",
+			"The _i variant ignores case",
+		},
+		Functions: template.FuncMap{
+			"filter_has_suffix": func(suffix string) FilterFunc {
+				return stringFilter(func(v string) bool {
+					return strings.HasSuffix(v, suffix)
+				})
+			},
+			"filter_has_suffix_i": func(suffix string) FilterFunc {
+				suffix = strings.ToLower(suffix)
+				return stringFilter(func(v string) bool {
+					return strings.HasSuffix(strings.ToLower(v), suffix)
+				})
+			},
+		},
+	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_match[_i] <regexp string>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks whether the value is a string matching regexp.",
+			"The regexp is compiled once when the filter is built. The _i variant ignores case",
+		},
+		Functions: template.FuncMap{
+			"filter_match": func(expr string) (FilterFunc, error) {
+				return regexpFilter(expr)
+			},
+			"filter_match_i": func(expr string) (FilterFunc, error) {
+				return regexpFilter("(?i)" + expr)
+			},
+		},
+	},
+	{
+		Category: filterCategory,
+		Syntax:   "filter_empty",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which checks whether the value is nil, an empty string, or an empty slice or map",
+		},
+		Functions: template.FuncMap{"filter_empty": func() FilterFunc {
+			return isEmpty
+		}},
+	},
 	{
 		Category:    filterCategory,
 		Syntax:      "filter_not <filter FilterFunc>",
@@ -79,9 +238,12 @@ var filterFuncs = []FunctionSet{
 		}},
 	},
 	{
-		Category:    filterCategory,
-		Syntax:      "filter_to_int <filter1 FilterFunc> ... <filterN FilterFunc>",
-		Description: []string{"Use with filter or first_match. Returns a FilterFunc which applies filters using the value converted to an int"},
+		Category: filterCategory,
+		Syntax:   "filter_to_int <filter1 FilterFunc> ... <filterN FilterFunc>",
+		Description: []string{
+			"Use with filter or first_match. Returns a FilterFunc which applies filters using the value converted to an int.",
+			"Values which cannot be converted, such as maps and non-numeric strings, don't match",
+		},
 		Functions: template.FuncMap{"filter_to_int": func(filters ...FilterFunc) FilterFunc {
 			return func(v any) bool {
 				switch v := v.(type) {
@@ -101,12 +263,9 @@ var filterFuncs = []FunctionSet{
 					return filterAnd(int(v), filters)
 				case string:
 					iv, err := strconv.Atoi(v)
-					if err != nil {
-						panic(err)
-					}
-					return filterAnd(iv, filters)
+					return err == nil && filterAnd(iv, filters)
 				default:
-					panic(fmt.Sprintf("Cannot convert %T to int", v))
+					return false
 				}
 			}
 		}},
@@ -131,3 +290,61 @@ func filterAnd(v any, filters []FilterFunc) bool {
 	}
 	return true
 }
+
+// stringFilter returns a FilterFunc which applies f to string values. Values of any other type never match.
+func stringFilter(f func(v string) bool) FilterFunc {
+	return func(v any) bool {
+		s, ok := v.(string)
+		return ok && f(s)
+	}
+}
+
+func regexpFilter(expr string) (FilterFunc, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return stringFilter(re.MatchString), nil
+}
+
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// typeName returns the JSON-like type name of v: string, number, bool, bytes, list, map, null, or an empty string
+// if v has no JSON counterpart.
+func typeName(v any) string {
+	if v == nil {
+		return "null"
+	}
+	if _, ok := v.(Bytes); ok {
+		return "bytes"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map:
+		return "map"
+	default:
+		return ""
+	}
+}
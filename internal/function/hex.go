@@ -0,0 +1,35 @@
+package function
+
+import (
+	"encoding/hex"
+	"text/template"
+)
+
+const hexCategory = "Hex"
+
+var hexFuncs = []FunctionSet{
+	{
+		Category:    hexCategory,
+		Syntax:      "hex_encode <val string|Bytes>",
+		Description: []string{"Encodes val in lower case hexadecimal"},
+		Functions:   template.FuncMap{"hex_encode": encoder(hex.EncodeToString)},
+	},
+	{
+		Category: hexCategory,
+		Syntax:   "hex_decode[_bytes] <val string|Bytes>",
+		Description: []string{
+			"Decodes val from hexadecimal (upper or lower case). The _bytes variant returns Bytes instead of a string.",
+			"Returns an error if val is not valid hexadecimal",
+		},
+		Functions: template.FuncMap{
+			"hex_decode":       stringDecoder(hexDecode),
+			"hex_decode_bytes": bytesDecoder(hexDecode),
+		},
+	},
+}
+
+func hexDecode(v []byte) ([]byte, error) {
+	data := make([]byte, hex.DecodedLen(len(v)))
+	n, err := hex.Decode(data, v)
+	return data[:n], err
+}
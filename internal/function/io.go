@@ -1,8 +1,13 @@
 package function
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
 	"text/template"
+	"time"
 )
 
 const ioCategory = "I/O"
@@ -11,7 +16,7 @@ var ioFuncs = []FunctionSet{
 	{
 		Category:    ioCategory,
 		Syntax:      "read_file <filename string>",
-		Description: []string{"Reads the given filename and returns its content as a string. Panics if an error occurs"},
+		Description: []string{"Reads the given filename, relative to the current directory, and returns its content as a string. Panics if an error occurs"},
 		Functions: template.FuncMap{"read_file": func(filename string) string {
 			data, err := os.ReadFile(filename)
 			if err != nil {
@@ -20,4 +25,116 @@ var ioFuncs = []FunctionSet{
 			return string(data)
 		}},
 	},
+	{
+		Category:    ioCategory,
+		Syntax:      "read_file_bytes <filename string>",
+		Description: []string{"Reads the given filename, relative to the current directory, and returns its content as Bytes, for binary files"},
+		Functions: template.FuncMap{"read_file_bytes": func(filename string) (Bytes, error) {
+			return os.ReadFile(filename)
+		}},
+	},
+	{
+		Category:    ioCategory,
+		Syntax:      "read_file_or <default string> <filename string>",
+		Description: []string{"Reads the given filename, relative to the current directory, and returns its content as a string, or default if the file does not exist"},
+		Functions: template.FuncMap{"read_file_or": func(def string, filename string) (string, error) {
+			data, err := os.ReadFile(filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				return def, nil
+			}
+			return string(data), err
+		}},
+	},
+	{
+		Category:    ioCategory,
+		Syntax:      "file_exists|is_dir <path string>",
+		Description: []string{"Returns whether path, relative to the current directory, exists/is a directory. Symbolic links are followed"},
+		Functions: template.FuncMap{
+			"file_exists": func(path string) bool {
+				_, err := os.Stat(path)
+				return err == nil
+			},
+			"is_dir": func(path string) bool {
+				info, err := os.Stat(path)
+				return err == nil && info.IsDir()
+			},
+		},
+	},
+	{
+		Category:    ioCategory,
+		Syntax:      "file_size <path string>",
+		Description: []string{"Returns the size of the file at path, relative to the current directory, in bytes"},
+		Functions: template.FuncMap{"file_size": func(path string) (int64, error) {
+			info, err := os.Stat(path)
+			if err != nil {
+				return 0, err
+			}
+			return info.Size(), nil
+		}},
+	},
+	{
+		Category:    ioCategory,
+		Syntax:      "file_mtime <path string>",
+		Description: []string{"Returns the modification time of the file at path, relative to the current directory"},
+		Functions: template.FuncMap{"file_mtime": func(path string) (time.Time, error) {
+			info, err := os.Stat(path)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return info.ModTime(), nil
+		}},
+	},
+	{
+		Category:    ioCategory,
+		Syntax:      "glob <pattern string>",
+		Description: []string{"Returns the sorted paths matching pattern (e.g. conf.d/*.conf), relative to the current directory. The result is empty if nothing matches"},
+		Functions: template.FuncMap{"glob": func(pattern string) ([]string, error) {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, err
+			}
+			slices.Sort(matches)
+			if matches == nil {
+				matches = []string{}
+			}
+			return matches, nil
+		}},
+	},
+	{
+		Category: ioCategory,
+		Syntax:   "read_dir <path string>",
+		Description: []string{
+			"Returns the entries of the directory at path, relative to the current directory, sorted by name.",
+			"Each entry is a map with the name, path (path joined with name), is_dir and type (file, dir, symlink or other) entries",
+		},
+		Functions: template.FuncMap{"read_dir": func(path string) ([]any, error) {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			res := make([]any, len(entries))
+			for i, entry := range entries {
+				res[i] = map[string]any{
+					"name":   entry.Name(),
+					"path":   filepath.Join(path, entry.Name()),
+					"is_dir": entry.IsDir(),
+					"type":   fileTypeName(entry.Type()),
+				}
+			}
+			return res, nil
+		}},
+	},
+}
+
+func fileTypeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "other"
+	}
 }
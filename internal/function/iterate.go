@@ -0,0 +1,248 @@
+package function
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const iterateCategory = "Higher-order"
+
+// boundTemplate is the template being executed, used to look up named templates and to parse expressions
+var boundTemplate *template.Template
+
+// expressionTemplate is a clone of boundTemplate in which expressions are parsed, so that they don't interfere with
+// the templates defined by the user
+var expressionTemplate *template.Template
+
+// expressions holds the compiled expressions, by expression
+var expressions = map[string]*compiledExpression{}
+
+// captures is the stack of the values captured by the expressions being evaluated
+var captures []any
+
+// captureFunc is the function appended to the pipeline of bare expressions to capture their value
+const captureFunc = "_capture"
+
+type compiledExpression struct {
+	t *template.Template
+	// captured is set for bare expressions, whose value is captured instead of their output
+	captured bool
+}
+
+// SetTemplate binds the template being executed to the library, so that higher-order functions can call the
+// templates it defines. It must be called before executing the template.
+func SetTemplate(t *template.Template) {
+	boundTemplate = t
+	expressionTemplate = nil
+	expressions = map[string]*compiledExpression{}
+}
+
+var iterateFuncs = []FunctionSet{
+	{
+		Category: iterateCategory,
+		Syntax:   "map_each <expr string> <v []any|map[string]any>",
+		Description: []string{
+			"Applies expr to each element of v and returns a new slice/map (with the same keys) containing the results.",
+			"expr is either the name of a template defined with define, an inline template ({{.name}}-{{.port}}), or an expression",
+			"which is evaluated as if enclosed in {{ }} (.name, add . 1). The element is passed as the dot.",
+			"Expressions return their value as is. The output of templates is trimmed, and converted to numbers or booleans",
+			"if it is their exact text representation",
+		},
+		Functions: template.FuncMap{"map_each": func(expr string, v any) (any, error) {
+			t, err := expression(expr)
+			if err != nil {
+				return nil, err
+			}
+			if isMap(v) {
+				m, err := toMap(v)
+				if err != nil {
+					return nil, err
+				}
+				ret := make(map[string]any, len(m))
+				for k, e := range m {
+					if ret[k], err = evaluate(t, e); err != nil {
+						return nil, err
+					}
+				}
+				return ret, nil
+			}
+
+			s, err := toSlice(v)
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]any, len(s))
+			for i, e := range s {
+				if ret[i], err = evaluate(t, e); err != nil {
+					return nil, err
+				}
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category: iterateCategory,
+		Syntax:   "reduce <expr string> <init any> <s []any|map[string]any>",
+		Description: []string{
+			"Applies expr to each element of s, passing a map with the acc, value and index entries as the dot.",
+			"acc is init for the first element, then the result of the previous evaluation. Returns the last result.",
+			"Maps are walked in key order, and the dot also has a key entry",
+			"See map_each for the syntax of expr and the conversion of results",
+		},
+		Functions: template.FuncMap{"reduce": func(expr string, init any, v any) (any, error) {
+			t, err := expression(expr)
+			if err != nil {
+				return nil, err
+			}
+			keys, s, err := elements(v)
+			if err != nil {
+				return nil, err
+			}
+			acc := init
+			for i, e := range s {
+				dot := map[string]any{"acc": acc, "value": e, "index": i}
+				if keys != nil {
+					dot["key"] = keys[i]
+				}
+				if acc, err = evaluate(t, dot); err != nil {
+					return nil, err
+				}
+			}
+			return acc, nil
+		}},
+	},
+	{
+		Category: iterateCategory,
+		Syntax:   "any|all <expr string> <s []any|map[string]any>",
+		Description: []string{
+			"Returns true if expr is true for at least one/all of the elements of s. See map_each for the syntax of expr.",
+			"A result is true unless it is empty, false or 0",
+		},
+		Functions: template.FuncMap{
+			"any": func(expr string, v any) (bool, error) {
+				return test(expr, v, true)
+			},
+			"all": func(expr string, v any) (bool, error) {
+				return test(expr, v, false)
+			},
+		},
+	},
+}
+
+// expression compiles expr, which is either the name of a template, an inline template or an expression
+func expression(expr string) (*compiledExpression, error) {
+	if boundTemplate == nil {
+		return nil, fmt.Errorf("No template bound to evaluate %q", expr)
+	}
+	if e, ok := expressions[expr]; ok {
+		return e, nil
+	}
+
+	e := &compiledExpression{t: boundTemplate.Lookup(expr)}
+	if e.t == nil {
+		if expressionTemplate == nil {
+			clone, err := boundTemplate.Clone()
+			if err != nil {
+				return nil, err
+			}
+			expressionTemplate = clone.Funcs(template.FuncMap{captureFunc: capture})
+		}
+
+		text := expr
+		if !strings.Contains(expr, "{{") {
+			text = "{{" + expr + " | " + captureFunc + "}}"
+			e.captured = true
+		}
+		// Expressions are named after themselves: as expr is not the name of a user template, there is no conflict
+		var err error
+		if e.t, err = expressionTemplate.New(expr).Parse(text); err != nil {
+			return nil, err
+		}
+	}
+	expressions[expr] = e
+	return e, nil
+}
+
+// capture records v as the value of the expression being evaluated
+func capture(v any) string {
+	captures[len(captures)-1] = v
+	return ""
+}
+
+// evaluate executes e with dot as the dot, and returns either the captured value or the converted output
+func evaluate(e *compiledExpression, dot any) (any, error) {
+	captures = append(captures, nil)
+	defer func() {
+		captures = captures[:len(captures)-1]
+	}()
+
+	var buf bytes.Buffer
+	if err := e.t.Execute(&buf, dot); err != nil {
+		return nil, err
+	}
+	if e.captured {
+		return captures[len(captures)-1], nil
+	}
+
+	out := strings.TrimSpace(buf.String())
+	if i, err := strconv.Atoi(out); err == nil && strconv.Itoa(i) == out {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(out, 64); err == nil && strconv.FormatFloat(f, 'g', -1, 64) == out {
+		return f, nil
+	}
+	if out == "true" || out == "false" {
+		return out == "true", nil
+	}
+	return out, nil
+}
+
+// test evaluates expr on the elements of v until one of them is true (if want is true) or false (if want is false)
+func test(expr string, v any, want bool) (bool, error) {
+	e, err := expression(expr)
+	if err != nil {
+		return false, err
+	}
+	_, s, err := elements(v)
+	if err != nil {
+		return false, err
+	}
+	for _, elem := range s {
+		res, err := evaluate(e, elem)
+		if err != nil {
+			return false, err
+		}
+		if truthy(res) == want {
+			return want, nil
+		}
+	}
+	return !want, nil
+}
+
+// elements returns the elements of v, which is either a slice or a map. For maps, the sorted keys are returned as well,
+// and the values are in the same order
+func elements(v any) ([]string, []any, error) {
+	if !isMap(v) {
+		s, err := toSlice(v)
+		return nil, s, err
+	}
+	m, err := toMap(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, _ := sortedKeys(m)
+	s := make([]any, len(keys))
+	for i, k := range keys {
+		s[i] = m[k]
+	}
+	return keys, s, nil
+}
+
+// truthy follows the definition of truth of if: false, 0, nil and empty values are false
+func truthy(v any) bool {
+	truth, _ := template.IsTrue(v)
+	return truth
+}
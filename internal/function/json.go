@@ -0,0 +1,241 @@
+package function
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const jsonCategory = "JSON"
+
+var jsonFuncs = []FunctionSet{
+	{
+		Category:    jsonCategory,
+		Syntax:      "to_json <v any>",
+		Description: []string{"Encodes v to compact JSON. Map keys are sorted and HTML characters are not escaped"},
+		Functions: template.FuncMap{"to_json": func(v any) (string, error) {
+			return marshalJSON(v, "")
+		}},
+	},
+	{
+		Category: jsonCategory,
+		Syntax:   "to_pretty_json [<indent int|string>] <v any>",
+		Description: []string{
+			"Encodes v to indented JSON. indent is either a number of spaces or the indentation string, and defaults to 2 spaces.",
+			"Map keys are sorted and HTML characters are not escaped",
+		},
+		Functions: template.FuncMap{"to_pretty_json": func(args ...any) (string, error) {
+			indent, v, err := indentArgs(args, 2)
+			if err != nil {
+				return "", err
+			}
+			return marshalJSON(v, indent)
+		}},
+	},
+	{
+		Category: jsonCategory,
+		Syntax:   "to_canonical_json <v any>",
+		Description: []string{
+			"Encodes v to canonical JSON (RFC 8785): no whitespace, sorted keys, and numbers formatted in their shortest form.",
+			"The output is stable and suitable for hashing",
+		},
+		Functions: template.FuncMap{"to_canonical_json": func(v any) (string, error) {
+			// Round-trip through encoding/json to handle any type, keeping the numbers literal
+			data, err := marshalJSON(v, "")
+			if err != nil {
+				return "", err
+			}
+			dec := json.NewDecoder(strings.NewReader(data))
+			dec.UseNumber()
+			var generic any
+			if err = dec.Decode(&generic); err != nil {
+				return "", err
+			}
+
+			var buf bytes.Buffer
+			if err = writeCanonicalJSON(&buf, generic); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}},
+	},
+	{
+		Category: jsonCategory,
+		Syntax:   "from_json <s string>",
+		Description: []string{
+			"Decodes the JSON string s and returns the resulting value. Errors report the line and column of the problem",
+		},
+		Functions: template.FuncMap{"from_json": func(s string) (any, error) {
+			return unmarshalJSON([]byte(s))
+		}},
+	},
+}
+
+func marshalJSON(v any, indent string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func unmarshalJSON(data []byte) (any, error) {
+	var v any
+	err := json.Unmarshal(data, &v)
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := position(data, syntaxErr.Offset)
+		return nil, fmt.Errorf("Invalid JSON at line %d, column %d: %w", line, col, err)
+	}
+	return v, err
+}
+
+// position returns the 1-based line and column of the given byte offset in data
+func position(data []byte, offset int64) (int, int) {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// indentArgs extracts the optional indentation and the value from the arguments of a function with the
+// [<indent int|string>] <v any> syntax
+func indentArgs(args []any, def int) (string, any, error) {
+	switch len(args) {
+	case 1:
+		return strings.Repeat(" ", def), args[0], nil
+	case 2:
+		switch indent := args[0].(type) {
+		case string:
+			return indent, args[1], nil
+		case int:
+			if indent < 0 {
+				return "", nil, fmt.Errorf("Invalid indentation: %d", indent)
+			}
+			return strings.Repeat(" ", indent), args[1], nil
+		default:
+			return "", nil, fmt.Errorf("Invalid indentation: %v", indent)
+		}
+	default:
+		return "", nil, fmt.Errorf("Invalid number of arguments: %d", len(args))
+	}
+}
+
+// writeCanonicalJSON writes v, as decoded by encoding/json with UseNumber, in canonical form
+func writeCanonicalJSON(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(canonicalNumber(f))
+	case string:
+		return writeCanonicalString(buf, v)
+	case []any:
+		buf.WriteByte('[')
+		for i := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, v[i]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// Keys are sorted on their UTF-16 code units
+		slices.SortFunc(keys, func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, k); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("Unsupported type: %T", v)
+	}
+	return nil
+}
+
+// writeCanonicalString writes s as JSON.stringify does, as required by RFC 8785: only quotes, backslashes and
+// control characters are escaped
+func writeCanonicalString(buf *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("Invalid UTF-8 string: %q", s)
+	}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+// canonicalNumber formats f like ECMAScript does, as required by RFC 8785
+func canonicalNumber(f float64) string {
+	abs := math.Abs(f)
+	if abs == 0 {
+		return "0"
+	}
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	sign := exp[0]
+	exp = strings.TrimLeft(exp[1:], "0")
+	return mantissa + "e" + string(sign) + exp
+}
@@ -0,0 +1,265 @@
+package function
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const jwtCategory = "JWT"
+
+var jwtFuncs = []FunctionSet{
+	{
+		Category: jwtCategory,
+		Syntax:   "jwt_sign <alg string> <key string|Bytes> <claims map[string]any> [<header map[string]any>]",
+		Description: []string{
+			"Returns a JWT containing claims, signed with key. alg is HS256 (key is the shared secret), RS256 or ES256",
+			"(key is a PEM encoded RSA or P-256 private key, such as returned by gen_private_key).",
+			"The entries of header, such as kid, are added to the JWT header",
+		},
+		Functions: template.FuncMap{"jwt_sign": func(alg string, key any, claims map[string]any, header ...map[string]any) (string, error) {
+			k, err := toBytes(key)
+			if err != nil {
+				return "", err
+			}
+			h := map[string]any{}
+			for i := range header {
+				h = deepMerge(h, header[i])
+			}
+			h["alg"] = alg
+			if _, ok := h["typ"]; !ok {
+				h["typ"] = "JWT"
+			}
+
+			encodedHeader, err := json.Marshal(h)
+			if err != nil {
+				return "", err
+			}
+			encodedClaims, err := json.Marshal(claims)
+			if err != nil {
+				return "", err
+			}
+			signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+			signature, err := jwtSignature(alg, k, signingInput)
+			if err != nil {
+				return "", err
+			}
+			return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+		}},
+	},
+	{
+		Category: jwtCategory,
+		Syntax:   "jwt_decode <token string>",
+		Description: []string{
+			"Decodes token without verifying it, and returns a map with the header, claims and signature (Base64 encoded) entries",
+		},
+		Functions: template.FuncMap{"jwt_decode": func(token string) (map[string]any, error) {
+			token = strings.TrimSpace(token)
+			header, claims, _, err := parseJWT(token)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"header":    header,
+				"claims":    claims,
+				"signature": token[strings.LastIndexByte(token, '.')+1:],
+			}, nil
+		}},
+	},
+	{
+		Category: jwtCategory,
+		Syntax:   "jwt_verify <key string|Bytes> <token string>",
+		Description: []string{
+			"Verifies the signature of token with key, and returns a map with the header and claims entries.",
+			"key is the shared secret for HS256, or a PEM encoded public key, certificate or private key for RS256 and ES256.",
+			"The algorithm is determined by key: tokens whose alg header doesn't match are rejected.",
+			"Fails if the signature is invalid, or if the token is expired or not valid yet according to its exp and nbf claims",
+		},
+		Functions: template.FuncMap{"jwt_verify": func(key any, token string) (map[string]any, error) {
+			k, err := toBytes(key)
+			if err != nil {
+				return nil, err
+			}
+			token = strings.TrimSpace(token)
+			header, claims, signature, err := parseJWT(token)
+			if err != nil {
+				return nil, err
+			}
+			alg, _ := header["alg"].(string)
+			signingInput := token[:strings.LastIndexByte(token, '.')]
+			if err = jwtVerify(alg, k, signingInput, signature); err != nil {
+				return nil, err
+			}
+
+			t := now()
+			if exp, ok := toFloat(claims["exp"]); ok && !t.Before(time.Unix(int64(exp), 0)) {
+				return nil, fmt.Errorf("Invalid JWT: expired")
+			}
+			if nbf, ok := toFloat(claims["nbf"]); ok && t.Before(time.Unix(int64(nbf), 0)) {
+				return nil, fmt.Errorf("Invalid JWT: not valid yet")
+			}
+			return map[string]any{"header": header, "claims": claims}, nil
+		}},
+	},
+}
+
+func parseJWT(token string) (map[string]any, map[string]any, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("Invalid JWT: expected 3 parts, got %d", len(parts))
+	}
+
+	var header, claims map[string]any
+	for i, target := range []*map[string]any{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Invalid JWT: %w", err)
+		}
+		if err = json.Unmarshal(data, target); err != nil {
+			return nil, nil, nil, fmt.Errorf("Invalid JWT: %w", err)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Invalid JWT: %w", err)
+	}
+	return header, claims, signature, nil
+}
+
+func jwtSignature(alg string, key []byte, signingInput string) ([]byte, error) {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "HS256":
+		if len(key) == 0 {
+			return nil, fmt.Errorf("Empty HS256 secret")
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signingInput))
+		return mac.Sum(nil), nil
+
+	case "RS256":
+		signer, err := parsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := signer.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("RS256 requires an RSA key, got %s", keyTypeName(signer))
+		}
+		return rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+
+	case "ES256":
+		signer, err := parsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		ecKey, ok := signer.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 requires a P-256 ECDSA key")
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed size R || S encoding
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+
+	default:
+		return nil, fmt.Errorf("Unsupported JWT algorithm: %s", alg)
+	}
+}
+
+// jwtVerify checks the signature of a token. The algorithm is determined by the key, as trusting the alg header
+// would allow to forge tokens, e.g. by using a public key as an HS256 secret: PEM keys are used for RS256 or ES256,
+// and anything else is an HS256 secret. Tokens using another algorithm are rejected.
+func jwtVerify(alg string, key []byte, signingInput string, signature []byte) error {
+	if block, _ := pem.Decode(key); block == nil {
+		if alg != "HS256" {
+			return fmt.Errorf("Invalid JWT: algorithm %s does not match the key, expected HS256", alg)
+		}
+		expected, err := jwtSignature(alg, key, signingInput)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(expected, signature) {
+			return fmt.Errorf("Invalid JWT: signature mismatch")
+		}
+		return nil
+	}
+
+	pub, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return fmt.Errorf("Invalid JWT: algorithm %s does not match the key, expected RS256", alg)
+		}
+		if err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("Invalid JWT: %w", err)
+		}
+		return nil
+
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return fmt.Errorf("ES256 requires a P-256 ECDSA key")
+		}
+		if alg != "ES256" {
+			return fmt.Errorf("Invalid JWT: algorithm %s does not match the key, expected ES256", alg)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("Invalid JWT: signature mismatch")
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return fmt.Errorf("Invalid JWT: signature mismatch")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("Unsupported JWT key type: %s", keyTypeName(pub))
+	}
+}
+
+// parsePublicKey returns the public key found in data, which is a PEM encoded public key, certificate or private key
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "PUBLIC KEY":
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			return x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return cert.PublicKey, nil
+		}
+	}
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("No public key found")
+	}
+	return key.Public(), nil
+}
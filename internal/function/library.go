@@ -41,8 +41,35 @@ func init() {
 	Functions = append(Functions, stringFuncs...)
 	Functions = append(Functions, regexpFuncs...)
 	Functions = append(Functions, mathFuncs...)
+	Functions = append(Functions, bytesFuncs...)
 	Functions = append(Functions, base64Funcs...)
+	Functions = append(Functions, base32Funcs...)
+	Functions = append(Functions, hexFuncs...)
+	Functions = append(Functions, ascii85Funcs...)
+	Functions = append(Functions, quotedPrintableFuncs...)
+	Functions = append(Functions, urlFuncs...)
+	Functions = append(Functions, cryptoFuncs...)
+	Functions = append(Functions, passwordFuncs...)
+	Functions = append(Functions, encryptionFuncs...)
+	Functions = append(Functions, x509Funcs...)
+	Functions = append(Functions, sshFuncs...)
+	Functions = append(Functions, wireguardFuncs...)
+	Functions = append(Functions, jwtFuncs...)
+	Functions = append(Functions, randomFuncs...)
+	Functions = append(Functions, timeFuncs...)
+	Functions = append(Functions, durationFuncs...)
+	Functions = append(Functions, cronFuncs...)
+	Functions = append(Functions, jsonFuncs...)
+	Functions = append(Functions, encodingFuncs...)
+	Functions = append(Functions, csvFuncs...)
 	Functions = append(Functions, ioFuncs...)
+	Functions = append(Functions, dataFuncs...)
 	Functions = append(Functions, mapSliceFuncs...)
+	Functions = append(Functions, collectionFuncs...)
+	Functions = append(Functions, mapFuncs...)
+	Functions = append(Functions, setFuncs...)
 	Functions = append(Functions, filterFuncs...)
+	Functions = append(Functions, iterateFuncs...)
+	Functions = append(Functions, sortFuncs...)
+	Functions = append(Functions, queryFuncs...)
 }
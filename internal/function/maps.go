@@ -0,0 +1,403 @@
+package function
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"text/template"
+)
+
+var mapFuncs = []FunctionSet{
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "keys|values <m map[string]any>",
+		Description: []string{"Returns the keys/values of m, in the order of the sorted keys"},
+		Functions: template.FuncMap{
+			"keys": func(m any) ([]string, error) {
+				return sortedKeys(m)
+			},
+			"values": func(m any) ([]any, error) {
+				mm, err := toMap(m)
+				if err != nil {
+					return nil, err
+				}
+				keys, _ := sortedKeys(mm)
+				values := make([]any, len(keys))
+				for i, k := range keys {
+					values[i] = mm[k]
+				}
+				return values, nil
+			},
+		},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "has_key <key string> <m map[string]any>",
+		Description: []string{"Returns true if m contains key, false otherwise"},
+		Functions: template.FuncMap{"has_key": func(k string, m any) (bool, error) {
+			mm, err := toMap(m)
+			if err != nil {
+				return false, err
+			}
+			_, ok := mm[k]
+			return ok, nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "get <key string> <default any> <m map[string]any>",
+		Description: []string{"Returns the value of key in m, or default if m does not contain key"},
+		Functions: template.FuncMap{"get": func(k string, def any, m any) (any, error) {
+			mm, err := toMap(m)
+			if err != nil {
+				return nil, err
+			}
+			if v, ok := mm[k]; ok {
+				return v, nil
+			}
+			return def, nil
+		}},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "deep_get <path string> <default any> <v any>",
+		Description: []string{
+			"Returns the value found at path in v (see filter_path for the path syntax), or default if the path does not exist",
+		},
+		Functions: template.FuncMap{"deep_get": func(path string, def any, v any) (any, error) {
+			elements, err := parsePath(path)
+			if err != nil {
+				return nil, err
+			}
+			if value, ok := lookupPath(v, elements); ok {
+				return value, nil
+			}
+			return def, nil
+		}},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "deep_set <path string> <value any> <m map[string]any>",
+		Description: []string{
+			"Returns a copy of m where the value at path (see filter_path for the path syntax) is set to value.",
+			"Missing intermediate maps are created. m itself is not modified",
+		},
+		Functions: template.FuncMap{"deep_set": func(path string, value any, m any) (map[string]any, error) {
+			elements, err := parsePath(path)
+			if err != nil {
+				return nil, err
+			}
+			if len(elements) == 0 || elements[0].isIndex {
+				return nil, fmt.Errorf("Invalid path %q: must start with a key", path)
+			}
+			mm, err := toMap(m)
+			if err != nil {
+				return nil, err
+			}
+			ret, err := setPath(mm, elements, value)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot set %q: %w", path, err)
+			}
+			return ret.(map[string]any), nil
+		}},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "unset <m map[string]any> <path1 string> ... <pathN string>",
+		Description: []string{
+			"Returns a copy of m where the values at the given paths (see filter_path for the path syntax) are removed.",
+			"Missing paths are ignored. m itself is not modified",
+		},
+		Functions: template.FuncMap{"unset": func(m any, paths ...string) (map[string]any, error) {
+			ret, err := toMap(m)
+			if err != nil {
+				return nil, err
+			}
+			ret = maps.Clone(ret)
+			for _, path := range paths {
+				elements, err := parsePath(path)
+				if err != nil {
+					return nil, err
+				}
+				if len(elements) == 0 || elements[0].isIndex {
+					return nil, fmt.Errorf("Invalid path %q: must start with a key", path)
+				}
+				ret = unsetPath(ret, elements).(map[string]any)
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category:    mapSliceCategory,
+		Syntax:      "omit|pick <m map[string]any> <key1 string> ... <keyN string>",
+		Description: []string{"Returns a copy of m without/with only the given keys"},
+		Functions: template.FuncMap{
+			"omit": func(m any, keys ...string) (map[string]any, error) {
+				mm, err := toMap(m)
+				if err != nil {
+					return nil, err
+				}
+				ret := make(map[string]any)
+				for k, v := range mm {
+					if !slices.Contains(keys, k) {
+						ret[k] = v
+					}
+				}
+				return ret, nil
+			},
+			"pick": func(m any, keys ...string) (map[string]any, error) {
+				mm, err := toMap(m)
+				if err != nil {
+					return nil, err
+				}
+				ret := make(map[string]any)
+				for _, k := range keys {
+					if v, ok := mm[k]; ok {
+						ret[k] = v
+					}
+				}
+				return ret, nil
+			},
+		},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "merge|deep_merge <m1 map[string]any> ... <mN map[string]any>",
+		Description: []string{
+			"Returns a new map containing the keys and values of all the maps. When a key appears in several maps, the last one wins.",
+			"deep_merge recursively merges the values which are maps in both sides, and copies the nested maps and slices.",
+			"The arguments are not modified",
+		},
+		Functions: template.FuncMap{
+			"merge": func(m ...any) (map[string]any, error) {
+				ret := make(map[string]any)
+				for i := range m {
+					mm, err := toMap(m[i])
+					if err != nil {
+						return nil, err
+					}
+					maps.Copy(ret, mm)
+				}
+				return ret, nil
+			},
+			"deep_merge": func(m ...any) (map[string]any, error) {
+				ret := make(map[string]any)
+				for i := range m {
+					mm, err := toMap(m[i])
+					if err != nil {
+						return nil, err
+					}
+					ret = deepMerge(ret, mm)
+				}
+				return ret, nil
+			},
+		},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "dict_to_list <m map[string]any>",
+		Description: []string{
+			"Converts m to a slice of maps with a key and a value entries, in the order of the sorted keys",
+		},
+		Functions: template.FuncMap{"dict_to_list": func(m any) ([]any, error) {
+			mm, err := toMap(m)
+			if err != nil {
+				return nil, err
+			}
+			keys, _ := sortedKeys(mm)
+			ret := make([]any, len(keys))
+			for i, k := range keys {
+				ret[i] = map[string]any{"key": k, "value": mm[k]}
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category: mapSliceCategory,
+		Syntax:   "list_to_dict <s []any>",
+		Description: []string{
+			"Converts s to a map. Each element of s is either a map with a key and a value entries, or a [key, value] slice.",
+			"Keys which are not strings are converted to strings",
+		},
+		Functions: template.FuncMap{"list_to_dict": func(s any) (map[string]any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			ret := make(map[string]any, len(v))
+			for i, e := range v {
+				var k, value any
+				if m, ok := e.(map[string]any); ok {
+					if k, ok = m["key"]; !ok {
+						return nil, fmt.Errorf("Element %d has no key", i)
+					}
+					value = m["value"]
+				} else if pair, err := toSlice(e); err == nil && len(pair) == 2 {
+					k, value = pair[0], pair[1]
+				} else {
+					return nil, fmt.Errorf("Element %d is neither a key/value map nor a pair", i)
+				}
+				ret[fmt.Sprint(k)] = value
+			}
+			return ret, nil
+		}},
+	},
+}
+
+// toMap converts any map with string keys to a map[string]any. map[string]any values are returned as is, so the
+// result must be copied before being modified.
+func toMap(v any) (map[string]any, error) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return v, nil
+	}
+
+	keys, err := sortedKeys(v)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]any, len(keys))
+	for _, k := range keys {
+		ret[k], _ = lookupPath(v, []pathElement{{key: k}})
+	}
+	return ret, nil
+}
+
+// setPath returns a copy of v where the value at path is set to value. Only the maps and slices along the path are
+// copied, and converted to map[string]any and []any. Missing map entries are created as maps, but slice indices must exist.
+func setPath(v any, path []pathElement, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	e := path[0]
+	if !e.isIndex {
+		m := map[string]any{}
+		if v != nil {
+			if !isMap(v) {
+				return nil, fmt.Errorf("%s is not a map", typeName(v))
+			}
+			mm, err := toMap(v)
+			if err != nil {
+				return nil, err
+			}
+			m = maps.Clone(mm)
+		}
+		child, err := setPath(m[e.key], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		m[e.key] = child
+		return m, nil
+	}
+
+	if typeName(v) != "list" {
+		return nil, fmt.Errorf("%s is not a list", typeName(v))
+	}
+	s, err := toSlice(v)
+	if err != nil {
+		return nil, err
+	}
+	index := e.index
+	if index < 0 {
+		index += len(s)
+	}
+	if index < 0 || index >= len(s) {
+		return nil, fmt.Errorf("Index out of range: %d", e.index)
+	}
+	s = slices.Clone(s)
+	child, err := setPath(s[index], path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	s[index] = child
+	return s, nil
+}
+
+// unsetPath returns a copy of v where the value at path is removed. v is returned unchanged if the path does not
+// exist. The copied maps and slices are converted to map[string]any and []any.
+func unsetPath(v any, path []pathElement) any {
+	if _, ok := lookupPath(v, path); !ok {
+		return v
+	}
+
+	e := path[0]
+	if !e.isIndex {
+		m, _ := toMap(v)
+		m = maps.Clone(m)
+		if len(path) == 1 {
+			delete(m, e.key)
+		} else {
+			m[e.key] = unsetPath(m[e.key], path[1:])
+		}
+		return m
+	}
+
+	s, _ := toSlice(v)
+	index := e.index
+	if index < 0 {
+		index += len(s)
+	}
+	if len(path) == 1 {
+		return slices.Delete(slices.Clone(s), index, index+1)
+	}
+	s = slices.Clone(s)
+	s[index] = unsetPath(s[index], path[1:])
+	return s
+}
+
+// deepMerge returns a new map containing the entries of dst and src. Values which are maps on both sides are
+// merged recursively, other values from src replace the ones from dst. The values from src are deep copied.
+func deepMerge(dst, src map[string]any) map[string]any {
+	ret := maps.Clone(dst)
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]any); ok {
+			if dstMap, ok := ret[k].(map[string]any); ok {
+				ret[k] = deepMerge(dstMap, srcMap)
+				continue
+			}
+		}
+		ret[k] = deepCopy(v)
+	}
+	return ret
+}
+
+// deepCopy returns a copy of v, copying the nested maps and slices. Maps and slices other than map[string]any,
+// []any and []map[string]any are converted to map[string]any and []any
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = deepCopy(e)
+		}
+		return s
+	case []map[string]any:
+		s := make([]map[string]any, len(v))
+		for i, e := range v {
+			s[i] = deepCopy(e).(map[string]any)
+		}
+		return s
+	case Bytes:
+		return slices.Clone(v)
+	}
+
+	if isMap(v) {
+		if m, err := toMap(v); err == nil {
+			return deepCopy(m)
+		}
+	} else if typeName(v) == "list" {
+		if s, err := toSlice(v); err == nil {
+			return deepCopy(s)
+		}
+	}
+	return v
+}
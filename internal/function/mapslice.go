@@ -2,6 +2,7 @@ package function
 
 import (
 	"fmt"
+	"reflect"
 	"text/template"
 )
 
@@ -20,8 +21,12 @@ var mapSliceFuncs = []FunctionSet{
 		Category:    mapSliceCategory,
 		Syntax:      "append <s []any> <val1 any> ... <valN any>",
 		Description: []string{"Appends val1 to valN to the slice s, and returns the resulting slice"},
-		Functions: template.FuncMap{"append": func(s []any, e ...any) []any {
-			return append(s, e...)
+		Functions: template.FuncMap{"append": func(s any, e ...any) ([]any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			return append(v, e...), nil
 		}},
 	},
 	{
@@ -37,7 +42,7 @@ var mapSliceFuncs = []FunctionSet{
 	{
 		Category:    mapSliceCategory,
 		Syntax:      "set <m map[string]any> <key1 string> <val1 any> ... <keyN string> <valN any>",
-		Description: []string{"Sets the given keys and values to the map m, and returns it"},
+		Description: []string{"Sets the given keys and values to the map m, and returns it. m is modified in place, see deep_set and merge for copying alternatives"},
 		Functions:   template.FuncMap{"set": mapSet},
 	},
 	{
@@ -45,17 +50,11 @@ var mapSliceFuncs = []FunctionSet{
 		Syntax:      "filter <v map[string]any|[]any> <filter1 FilterFunc> ... <filterN FilterFunc>",
 		Description: []string{"Returns a new map/slice containing the elements matching the filters. Filters are built using filter_* functions"},
 		Functions: template.FuncMap{"filter": func(e any, filters ...FilterFunc) any {
-			switch v := e.(type) {
-			case []any:
-				filtered := make([]any, 0, len(v))
-				for i := range v {
-					if filterAnd(v[i], filters) {
-						filtered = append(filtered, v[i])
-					}
+			if isMap(e) {
+				v, err := toMap(e)
+				if err != nil {
+					panic(err)
 				}
-				return filtered
-
-			case map[string]any:
 				filtered := make(map[string]any)
 				for k, v := range v {
 					if filterAnd(v, filters) {
@@ -63,31 +62,50 @@ var mapSliceFuncs = []FunctionSet{
 					}
 				}
 				return filtered
+			}
 
-			default:
-				panic(fmt.Sprintf("Unsupported type: %T", v))
+			v, err := toSlice(e)
+			if err != nil {
+				panic(err)
 			}
+			filtered := make([]any, 0, len(v))
+			for i := range v {
+				if filterAnd(v[i], filters) {
+					filtered = append(filtered, v[i])
+				}
+			}
+			return filtered
 		}},
 	},
 	{
-		Category:    mapSliceCategory,
-		Syntax:      "first_match <v map[string]any|[]any> <filter1 FilterFunc> ... <filterN FilterFunc>",
-		Description: []string{"Returns the first value of v which matches all the filters. Filters are build using filter_* functions"},
+		Category: mapSliceCategory,
+		Syntax:   "first_match <v map[string]any|[]any> <filter1 FilterFunc> ... <filterN FilterFunc>",
+		Description: []string{
+			"Returns the first value of v which matches all the filters. Filters are build using filter_* functions.",
+			"Maps are walked in the order of their sorted keys",
+		},
 		Functions: template.FuncMap{"first_match": func(e any, filters ...FilterFunc) any {
-			switch v := e.(type) {
-			case []any:
-				for i := range v {
-					if filterAnd(v[i], filters) {
-						return v[i]
+			if isMap(e) {
+				v, err := toMap(e)
+				if err != nil {
+					return nil
+				}
+				keys, _ := sortedKeys(v)
+				for _, k := range keys {
+					if filterAnd(v[k], filters) {
+						return v[k]
 					}
 				}
 				return nil
+			}
 
-			case map[string]any:
-				for _, v := range v {
-					if filterAnd(v, filters) {
-						return v
-					}
+			v, err := toSlice(e)
+			if err != nil {
+				return nil
+			}
+			for i := range v {
+				if filterAnd(v[i], filters) {
+					return v[i]
 				}
 			}
 			return nil
@@ -108,3 +126,28 @@ func mapSet(m map[string]any, kv ...any) map[string]any {
 	}
 	return m
 }
+
+// toSlice converts any slice or array to a []any. nil is converted to an empty slice.
+// []any values are returned as is, so the result must be copied before being modified.
+func toSlice(v any) ([]any, error) {
+	switch v := v.(type) {
+	case nil:
+		return []any{}, nil
+	case []any:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("Unsupported type: %T", v)
+	}
+	s := make([]any, rv.Len())
+	for i := range s {
+		s[i] = rv.Index(i).Interface()
+	}
+	return s, nil
+}
+
+func isMap(v any) bool {
+	return v != nil && reflect.ValueOf(v).Kind() == reflect.Map
+}
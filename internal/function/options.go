@@ -0,0 +1,60 @@
+package function
+
+import (
+	"fmt"
+	"slices"
+)
+
+// optionArgs extracts the optional options map and the value from the arguments of a function with the
+// [<options map[string]any>] <v any> syntax
+func optionArgs(args []any) (map[string]any, any, error) {
+	switch len(args) {
+	case 1:
+		return map[string]any{}, args[0], nil
+	case 2:
+		options, err := toMap(args[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid options: %w", err)
+		}
+		return options, args[1], nil
+	default:
+		return nil, nil, fmt.Errorf("Invalid number of arguments: %d", len(args))
+	}
+}
+
+// checkOptions returns an error if options contains a key which is not allowed
+func checkOptions(options map[string]any, allowed ...string) error {
+	keys, _ := sortedKeys(options)
+	for _, k := range keys {
+		if !slices.Contains(allowed, k) {
+			return fmt.Errorf("Unknown option: %s", k)
+		}
+	}
+	return nil
+}
+
+// intOption returns the value of the integer option k, or def if it is not set
+func intOption(options map[string]any, k string, def int) (int, error) {
+	v, ok := options[k]
+	if !ok {
+		return def, nil
+	}
+	f, ok := toFloat(v)
+	if !ok || f != float64(int(f)) {
+		return 0, fmt.Errorf("Invalid value for option %s: %v", k, v)
+	}
+	return int(f), nil
+}
+
+// stringOption returns the value of the string option k, or def if it is not set
+func stringOption(options map[string]any, k string, def string) (string, error) {
+	v, ok := options[k]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("Invalid value for option %s: %v", k, v)
+	}
+	return s, nil
+}
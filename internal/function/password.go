@@ -0,0 +1,351 @@
+package function
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/blowfish"
+)
+
+const passwordCategory = "Passwords"
+
+var passwordFuncs = []FunctionSet{
+	{
+		Category: passwordCategory,
+		Syntax:   "bcrypt [<options map[string]any>] <password string>",
+		Description: []string{
+			"Hashes password with bcrypt. The following options are supported:",
+			"  cost (int): the cost, between 4 and 31, defaults to 10",
+			"  salt (string): the salt, as the 22 characters which follow the cost in bcrypt hashes. Defaults to a random salt",
+		},
+		Functions: template.FuncMap{"bcrypt": func(args ...any) (string, error) {
+			options, password, err := optionArgs(args)
+			if err != nil {
+				return "", err
+			}
+			return bcryptHash("2a", options, password)
+		}},
+	},
+	{
+		Category: passwordCategory,
+		Syntax:   "argon2id [<options map[string]any>] <password string>",
+		Description: []string{
+			"Hashes password with Argon2id, and returns the hash in the PHC string format. The following options are supported:",
+			"  time (int): the number of passes over the memory, defaults to 2",
+			"  memory (int): the memory to use in KiB, defaults to 19456",
+			"  threads (int): the degree of parallelism, defaults to 1",
+			"  key_length (int): the length of the hash in bytes, defaults to 32",
+			"  salt (string): the salt, defaults to 16 random bytes",
+		},
+		Functions: template.FuncMap{"argon2id": func(args ...any) (string, error) {
+			options, password, err := optionArgs(args)
+			if err != nil {
+				return "", err
+			}
+			if err = checkOptions(options, "time", "memory", "threads", "key_length", "salt"); err != nil {
+				return "", err
+			}
+			p, ok := password.(string)
+			if !ok {
+				return "", fmt.Errorf("Unsupported type: %T", password)
+			}
+
+			params := map[string]int{"time": 2, "memory": 19456, "threads": 1, "key_length": 32}
+			for k, def := range params {
+				if params[k], err = intOption(options, k, def); err != nil {
+					return "", err
+				}
+				if params[k] <= 0 || (k == "threads" && params[k] > 255) {
+					return "", fmt.Errorf("Invalid value for option %s: %d", k, params[k])
+				}
+			}
+			salt, err := stringOption(options, "salt", "")
+			if err != nil {
+				return "", err
+			}
+			if salt == "" {
+				if salt, err = randomString(16, ""); err != nil {
+					return "", err
+				}
+			}
+
+			key := argon2.IDKey([]byte(p), []byte(salt), uint32(params["time"]), uint32(params["memory"]), uint8(params["threads"]), uint32(params["key_length"]))
+			return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params["memory"], params["time"], params["threads"],
+				base64.RawStdEncoding.EncodeToString([]byte(salt)), base64.RawStdEncoding.EncodeToString(key)), nil
+		}},
+	},
+	{
+		Category: passwordCategory,
+		Syntax:   "sha256_crypt|sha512_crypt [<options map[string]any>] <password string>",
+		Description: []string{
+			"Hashes password with SHA-crypt, producing $5$/$6$ hashes as found in /etc/shadow. The following options are supported:",
+			"  rounds (int): the number of rounds, between 1000 and 999999999, defaults to 5000",
+			"  salt (string): the salt, up to 16 characters. Defaults to a random salt",
+		},
+		Functions: template.FuncMap{
+			"sha256_crypt": func(args ...any) (string, error) {
+				return shaCryptArgs(args, "5", sha256.New, sha256CryptPermutation)
+			},
+			"sha512_crypt": func(args ...any) (string, error) {
+				return shaCryptArgs(args, "6", sha512.New, sha512CryptPermutation)
+			},
+		},
+	},
+	{
+		Category: passwordCategory,
+		Syntax:   "htpasswd_line [<options map[string]any>] <user string> <password string>",
+		Description: []string{
+			"Returns a line for an Apache/nginx htpasswd file, with password hashed with bcrypt.",
+			"Supports the same options as bcrypt",
+		},
+		Functions: template.FuncMap{"htpasswd_line": func(args ...any) (string, error) {
+			if len(args) < 2 {
+				return "", fmt.Errorf("Invalid number of arguments: %d", len(args))
+			}
+			options, user, err := optionArgs(args[:len(args)-1])
+			if err != nil {
+				return "", err
+			}
+			u, ok := user.(string)
+			if !ok {
+				return "", fmt.Errorf("Unsupported type: %T", user)
+			}
+			if u == "" || strings.ContainsAny(u, ":\n") {
+				return "", fmt.Errorf("Invalid user name: %q", u)
+			}
+			h, err := bcryptHash("2y", options, args[len(args)-1])
+			if err != nil {
+				return "", err
+			}
+			return u + ":" + h, nil
+		}},
+	},
+}
+
+// cryptAlphabet is the alphabet used by bcrypt and SHA-crypt to encode salts and hashes
+const cryptAlphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// shaCryptAlphabet is the same set of characters as cryptAlphabet in the order used by SHA-crypt
+const shaCryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var bcryptEncoding = base64.NewEncoding(cryptAlphabet).WithPadding(base64.NoPadding)
+
+// randomString returns a random string of n characters from alphabet, or of n random bytes if alphabet is empty.
+// Salts always come from crypto/rand.
+func randomString(n int, alphabet string) (string, error) {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	if alphabet != "" {
+		for i := range data {
+			data[i] = alphabet[int(data[i])%len(alphabet)]
+		}
+	}
+	return string(data), nil
+}
+
+// bcryptHash implements bcrypt, as x/crypto/bcrypt does not allow to choose the salt
+func bcryptHash(version string, options map[string]any, password any) (string, error) {
+	if err := checkOptions(options, "cost", "salt"); err != nil {
+		return "", err
+	}
+	p, ok := password.(string)
+	if !ok {
+		return "", fmt.Errorf("Unsupported type: %T", password)
+	}
+	if len(p) > 72 {
+		return "", fmt.Errorf("bcrypt passwords cannot be longer than 72 bytes")
+	}
+	cost, err := intOption(options, "cost", 10)
+	if err != nil {
+		return "", err
+	}
+	if cost < 4 || cost > 31 {
+		return "", fmt.Errorf("Invalid bcrypt cost: %d", cost)
+	}
+
+	var salt []byte
+	encodedSalt, err := stringOption(options, "salt", "")
+	if err != nil {
+		return "", err
+	}
+	if encodedSalt == "" {
+		s, err := randomString(16, "")
+		if err != nil {
+			return "", err
+		}
+		salt = []byte(s)
+		encodedSalt = bcryptEncoding.EncodeToString(salt)
+	} else {
+		if len(encodedSalt) != 22 {
+			return "", fmt.Errorf("Invalid bcrypt salt: must be 22 characters long")
+		}
+		if salt, err = bcryptEncoding.DecodeString(encodedSalt); err != nil {
+			return "", fmt.Errorf("Invalid bcrypt salt: %w", err)
+		}
+		// The last character only carries 2 bits, normalize it
+		encodedSalt = bcryptEncoding.EncodeToString(salt)
+	}
+
+	// The trailing NUL is part of the key, as in the C implementation
+	key := append([]byte(p), 0)
+	c, err := blowfish.NewSaltedCipher(key, salt)
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < 1<<cost; i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+
+	data := []byte("OrpheanBeholderScryDoubt")
+	for i := 0; i < len(data); i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(data[i:i+8], data[i:i+8])
+		}
+	}
+	// Only 23 of the 24 bytes are encoded, as in the C implementation
+	return fmt.Sprintf("$%s$%02d$%s%s", version, cost, encodedSalt, bcryptEncoding.EncodeToString(data[:23])), nil
+}
+
+// The order in which SHA-crypt encodes the bytes of the hash, in groups of 3 bytes
+var (
+	sha256CryptPermutation = []int{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14, 15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29, -1, 31, 30,
+	}
+	sha512CryptPermutation = []int{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51, 31, 52, 10,
+		53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35, 15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19, 62, 20, 41,
+		-1, -1, 63,
+	}
+)
+
+func shaCryptArgs(args []any, id string, newHash func() hash.Hash, permutation []int) (string, error) {
+	options, password, err := optionArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if err = checkOptions(options, "rounds", "salt"); err != nil {
+		return "", err
+	}
+	p, ok := password.(string)
+	if !ok {
+		return "", fmt.Errorf("Unsupported type: %T", password)
+	}
+	rounds, err := intOption(options, "rounds", 5000)
+	if err != nil {
+		return "", err
+	}
+	rounds = max(1000, min(rounds, 999999999))
+	salt, err := stringOption(options, "salt", "")
+	if err != nil {
+		return "", err
+	}
+	if salt == "" {
+		if salt, err = randomString(16, shaCryptAlphabet); err != nil {
+			return "", err
+		}
+	}
+	if strings.ContainsAny(salt, "$:\n") {
+		return "", fmt.Errorf("Invalid salt: %q", salt)
+	}
+	salt = salt[:min(len(salt), 16)]
+
+	prefix := "$" + id + "$"
+	if rounds != 5000 {
+		prefix += "rounds=" + strconv.Itoa(rounds) + "$"
+	}
+	return prefix + salt + "$" + shaCrypt([]byte(p), []byte(salt), rounds, newHash, permutation), nil
+}
+
+// shaCrypt implements the SHA-crypt algorithm as specified in https://www.akkadia.org/drepper/SHA-crypt.txt
+func shaCrypt(password, salt []byte, rounds int, newHash func() hash.Hash, permutation []int) string {
+	sum := func(parts ...[]byte) []byte {
+		h := newHash()
+		for _, p := range parts {
+			h.Write(p)
+		}
+		return h.Sum(nil)
+	}
+	// repeat returns a sequence of length bytes made of block repeated
+	repeat := func(block []byte, length int) []byte {
+		ret := make([]byte, 0, length)
+		for len(ret) < length {
+			ret = append(ret, block[:min(len(block), length-len(ret))]...)
+		}
+		return ret
+	}
+
+	b := sum(password, salt, password)
+
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(repeat(b, len(password)))
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(password)
+		}
+	}
+	a := h.Sum(nil)
+
+	h.Reset()
+	for range password {
+		h.Write(password)
+	}
+	p := repeat(h.Sum(nil), len(password))
+
+	h.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(salt)
+	}
+	s := repeat(h.Sum(nil), len(salt))
+
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i%2 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(a)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i%2 != 0 {
+			h.Write(a)
+		} else {
+			h.Write(p)
+		}
+		a = h.Sum(a[:0])
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(permutation); i += 3 {
+		var w, n uint
+		for j, index := range permutation[i : i+3] {
+			if index >= 0 {
+				w |= uint(a[index]) << (8 * (2 - j))
+				n++
+			}
+		}
+		for range n + 1 {
+			out.WriteByte(shaCryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,105 @@
+package function
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathElement is one step of a path: either a map key or a slice index.
+type pathElement struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath parses a dotted path such as spec.ports[0].name into its elements.
+// Keys containing dots or brackets can be quoted: metadata.labels["app.kubernetes.io/name"].
+// A leading $ or . is ignored, and an empty path designates the value itself.
+func parsePath(path string) ([]pathElement, error) {
+	p := strings.TrimPrefix(path, "$")
+	p = strings.TrimPrefix(p, ".")
+	elements := []pathElement{}
+
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			if i+1 >= len(p) || p[i+1] == '.' || p[i+1] == '[' {
+				return nil, fmt.Errorf("Invalid path %q: empty key at offset %d", path, i)
+			}
+			i++
+
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("Invalid path %q: missing ]", path)
+			}
+			inner := p[i+1 : i+end]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				elements = append(elements, pathElement{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(strings.TrimSpace(inner))
+				if err != nil {
+					return nil, fmt.Errorf("Invalid path %q: invalid index %q", path, inner)
+				}
+				elements = append(elements, pathElement{index: index, isIndex: true})
+			}
+			i += end + 1
+
+		default:
+			end := strings.IndexAny(p[i:], ".[")
+			if end < 0 {
+				end = len(p) - i
+			}
+			elements = append(elements, pathElement{key: p[i : i+end]})
+			i += end
+		}
+	}
+
+	return elements, nil
+}
+
+// lookupPath follows the path elements from v. The second return value is false if the path does not exist in v.
+// Negative indices count from the end of slices.
+func lookupPath(v any, elements []pathElement) (any, bool) {
+	for _, e := range elements {
+		if v == nil {
+			return nil, false
+		}
+
+		if !e.isIndex {
+			if m, ok := v.(map[string]any); ok {
+				if v, ok = m[e.key]; !ok {
+					return nil, false
+				}
+				continue
+			}
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			value := rv.MapIndex(reflect.ValueOf(e.key).Convert(rv.Type().Key()))
+			if !value.IsValid() {
+				return nil, false
+			}
+			v = value.Interface()
+			continue
+		}
+
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, false
+		}
+		index := e.index
+		if index < 0 {
+			index += rv.Len()
+		}
+		if index < 0 || index >= rv.Len() {
+			return nil, false
+		}
+		v = rv.Index(index).Interface()
+	}
+
+	return v, true
+}
@@ -0,0 +1,34 @@
+package function
+
+import (
+	"text/template"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+const queryCategory = "Query"
+
+var queryFuncs = []FunctionSet{
+	{
+		Category: queryCategory,
+		Syntax:   "query <expr string> <v any>",
+		Description: []string{
+			"Evaluates the JMESPath expression expr against v and returns the result, which can be a scalar, a slice or a map.",
+			"Returns nil if expr does not match anything. See https://jmespath.org/specification.html for the syntax",
+		},
+		Functions: template.FuncMap{"query": func(expr string, v any) (any, error) {
+			compiled, ok := queryCache[expr]
+			if !ok {
+				var err error
+				if compiled, err = jmespath.Compile(expr); err != nil {
+					return nil, err
+				}
+				queryCache[expr] = compiled
+			}
+			return compiled.Search(v)
+		}},
+	},
+}
+
+// queryCache holds the compiled JMESPath expressions, so that queries used in loops are only compiled once
+var queryCache = map[string]*jmespath.JMESPath{}
@@ -0,0 +1,49 @@
+package function
+
+import (
+	"bytes"
+	"io"
+	"mime/quotedprintable"
+	"text/template"
+)
+
+const quotedPrintableCategory = "Quoted-printable"
+
+var quotedPrintableFuncs = []FunctionSet{
+	{
+		Category:    quotedPrintableCategory,
+		Syntax:      "quoted_printable_encode <val string|Bytes>",
+		Description: []string{"Encodes val in quoted-printable (RFC 2045). Lines are wrapped at 76 characters"},
+		Functions: template.FuncMap{"quoted_printable_encode": func(v any) (string, error) {
+			data, err := toBytes(v)
+			if err != nil {
+				return "", err
+			}
+			var buf bytes.Buffer
+			w := quotedprintable.NewWriter(&buf)
+			if _, err = w.Write(data); err != nil {
+				return "", err
+			}
+			if err = w.Close(); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}},
+	},
+	{
+		Category: quotedPrintableCategory,
+		Syntax:   "quoted_printable_decode[_bytes] <val string|Bytes>",
+		Description: []string{
+			"Decodes val from quoted-printable. The _bytes variant returns Bytes instead of a string.",
+			"Returns an error if val contains an invalid = sequence",
+		},
+		Functions: template.FuncMap{
+			"quoted_printable_decode":       stringDecoder(quotedPrintableDecode),
+			"quoted_printable_decode_bytes": bytesDecoder(quotedPrintableDecode),
+		},
+	},
+}
+
+func quotedPrintableDecode(v []byte) ([]byte, error) {
+	return io.ReadAll(quotedprintable.NewReader(bytes.NewReader(v)))
+}
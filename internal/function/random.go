@@ -0,0 +1,292 @@
+package function
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand/v2"
+	"strings"
+	"text/template"
+)
+
+const randomCategory = "Random"
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+)
+
+// rng is the generator used by the random functions. It draws from crypto/rand unless SetSeed is called
+var rng = mrand.New(cryptoSource{})
+
+// SetSeed makes the random functions deterministic, by drawing from a generator seeded with seed.
+// Key generation and password salts always use crypto/rand.
+func SetSeed(seed string) {
+	rng = mrand.New(mrand.NewChaCha8(sha256.Sum256([]byte(seed))))
+}
+
+// cryptoSource is a math/rand/v2 source backed by crypto/rand
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	rand.Read(b[:])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// uuidNamespaces contains the predefined UUID namespaces of RFC 9562
+var uuidNamespaces = map[string]string{
+	"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+	"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+}
+
+var randomFuncs = []FunctionSet{
+	{
+		Category: randomCategory,
+		Syntax:   "uuid_v4",
+		Description: []string{
+			"Returns a random UUID (version 4)",
+		},
+		Functions: template.FuncMap{"uuid_v4": func() string {
+			var u [16]byte
+			randomBytes(u[:])
+			return formatUUID(u, 4)
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "uuid_v5 <namespace string> <name string>",
+		Description: []string{
+			"Returns the name-based UUID (version 5) of name in namespace. The same arguments always give the same UUID.",
+			"namespace is either dns, url, oid, x500 or a UUID",
+		},
+		Functions: template.FuncMap{"uuid_v5": func(namespace string, name string) (string, error) {
+			ns, err := parseUUID(namespace)
+			if err != nil {
+				return "", err
+			}
+			h := sha1.New()
+			h.Write(ns[:])
+			h.Write([]byte(name))
+			var u [16]byte
+			copy(u[:], h.Sum(nil))
+			return formatUUID(u, 5), nil
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "uuid_v7",
+		Description: []string{
+			"Returns a time-ordered UUID (version 7), made of the current time in milliseconds followed by random bits",
+		},
+		Functions: template.FuncMap{"uuid_v7": func() string {
+			var u [16]byte
+			binary.BigEndian.PutUint64(u[:8], uint64(now().UnixMilli())<<16)
+			randomBytes(u[6:])
+			return formatUUID(u, 7)
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "rand_int <lo int> <hi int>",
+		Description: []string{
+			"Returns a random integer between lo and hi, both inclusive",
+		},
+		Functions: template.FuncMap{"rand_int": func(lo, hi int) (int, error) {
+			if hi < lo {
+				return 0, fmt.Errorf("Invalid range: %d > %d", lo, hi)
+			}
+			// The span is computed on unsigned integers, as it overflows int for wide ranges
+			span := uint64(hi) - uint64(lo) + 1
+			if span == 0 {
+				// The range covers all the 64 bits integers
+				return int(rng.Uint64()), nil
+			}
+			return lo + int(rng.Uint64N(span)), nil
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "rand_alphanum <n int>",
+		Description: []string{
+			"Returns a random string of n letters and digits",
+		},
+		Functions: template.FuncMap{"rand_alphanum": func(n int) (string, error) {
+			if n < 0 {
+				return "", fmt.Errorf("Invalid length: %d", n)
+			}
+			return randomChars(n, lowerChars+upperChars+digitChars), nil
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "rand_password [<options map[string]any>] <length int>",
+		Description: []string{
+			"Returns a random password of the given length. The following options are supported:",
+			"  lower, upper, digits, symbols (bool): whether to use the character class, all default to true",
+			"  min_lower, min_upper, min_digits, min_symbols (int): the minimum number of characters of the class, default to 1 when the class is used",
+			"  symbol_set (string): the symbols to choose from, defaults to " + symbolChars,
+			"  exclude (string): characters never to use, e.g. ambiguous ones such as 0O1lI",
+		},
+		Functions: template.FuncMap{"rand_password": func(args ...any) (string, error) {
+			options, v, err := optionArgs(args)
+			if err != nil {
+				return "", err
+			}
+			length, ok := toFloat(v)
+			if !ok || length != float64(int(length)) || length < 0 {
+				return "", fmt.Errorf("Invalid length: %v", v)
+			}
+			return randomPassword(options, int(length))
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "shuffle <s []any>",
+		Description: []string{
+			"Returns a copy of s with its elements in random order",
+		},
+		Functions: template.FuncMap{"shuffle": func(s any) ([]any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			res := append([]any{}, v...)
+			rng.Shuffle(len(res), func(i, j int) {
+				res[i], res[j] = res[j], res[i]
+			})
+			return res, nil
+		}},
+	},
+	{
+		Category: randomCategory,
+		Syntax:   "rand_choice <s []any>",
+		Description: []string{
+			"Returns a random element of s. Fails if s is empty",
+		},
+		Functions: template.FuncMap{"rand_choice": func(s any) (any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			if len(v) == 0 {
+				return nil, fmt.Errorf("Cannot choose from an empty list")
+			}
+			return v[rng.IntN(len(v))], nil
+		}},
+	},
+}
+
+func randomBytes(b []byte) {
+	for i := range b {
+		b[i] = byte(rng.Uint32())
+	}
+}
+
+func randomChars(n int, alphabet string) string {
+	var sb strings.Builder
+	for range n {
+		sb.WriteByte(alphabet[rng.IntN(len(alphabet))])
+	}
+	return sb.String()
+}
+
+// formatUUID sets the version and variant bits of u and returns its canonical text form
+func formatUUID(u [16]byte, version byte) string {
+	u[6] = u[6]&0x0f | version<<4
+	u[8] = u[8]&0x3f | 0x80
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	if ns, ok := uuidNamespaces[strings.ToLower(s)]; ok {
+		s = ns
+	}
+	h := strings.ReplaceAll(strings.Trim(s, "{}"), "-", "")
+	if len(h) != 32 {
+		return u, fmt.Errorf("Invalid UUID: %s", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(h)); err != nil {
+		return u, fmt.Errorf("Invalid UUID: %s", s)
+	}
+	return u, nil
+}
+
+func randomPassword(options map[string]any, length int) (string, error) {
+	if err := checkOptions(options, "lower", "upper", "digits", "symbols", "min_lower", "min_upper", "min_digits", "min_symbols",
+		"symbol_set", "exclude"); err != nil {
+		return "", err
+	}
+	symbols, err := stringOption(options, "symbol_set", symbolChars)
+	if err != nil {
+		return "", err
+	}
+	exclude, err := stringOption(options, "exclude", "")
+	if err != nil {
+		return "", err
+	}
+
+	var password []byte
+	var all string
+	for _, class := range []struct{ name, chars string }{
+		{"lower", lowerChars}, {"upper", upperChars}, {"digits", digitChars}, {"symbols", symbols},
+	} {
+		enabled := true
+		if v, ok := options[class.name]; ok {
+			if enabled, ok = v.(bool); !ok {
+				return "", fmt.Errorf("Invalid value for option %s: %v", class.name, v)
+			}
+		}
+		minName := "min_" + class.name
+		def := 0
+		if enabled {
+			def = 1
+		}
+		minCount, err := intOption(options, minName, def)
+		if err != nil {
+			return "", err
+		}
+		if !enabled {
+			if minCount > 0 {
+				return "", fmt.Errorf("Invalid value for option %s: %s are disabled", minName, class.name)
+			}
+			continue
+		}
+
+		chars := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(exclude, r) {
+				return -1
+			}
+			return r
+		}, class.chars)
+		if chars == "" {
+			if minCount > 0 {
+				return "", fmt.Errorf("No characters left for %s", class.name)
+			}
+			continue
+		}
+		password = append(password, randomChars(minCount, chars)...)
+		all += chars
+	}
+
+	if all == "" {
+		return "", fmt.Errorf("No characters to choose from")
+	}
+	if len(password) > length {
+		return "", fmt.Errorf("Invalid length: %d is less than the sum of the minimum counts (%d)", length, len(password))
+	}
+	password = append(password, randomChars(length-len(password), all)...)
+	rng.Shuffle(len(password), func(i, j int) {
+		password[i], password[j] = password[j], password[i]
+	})
+	return string(password), nil
+}
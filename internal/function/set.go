@@ -0,0 +1,142 @@
+package function
+
+import (
+	"text/template"
+)
+
+const setCategory = "Sets"
+
+var setFuncs = []FunctionSet{
+	{
+		Category: setCategory,
+		Syntax:   "union <s1 []any> ... <sN []any>",
+		Description: []string{
+			"Returns the values appearing in at least one of the slices, without duplicates, in the order of their first appearance",
+		},
+		Functions: template.FuncMap{"union": func(s ...any) ([]any, error) {
+			ret := []any{}
+			for i := range s {
+				v, err := toSlice(s[i])
+				if err != nil {
+					return nil, err
+				}
+				for _, e := range v {
+					if indexOf(e, ret) < 0 {
+						ret = append(ret, e)
+					}
+				}
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category:    setCategory,
+		Syntax:      "intersect <s1 []any> ... <sN []any>",
+		Description: []string{"Returns the values of s1 appearing in all the other slices, without duplicates"},
+		Functions: template.FuncMap{"intersect": func(s1 any, s ...any) ([]any, error) {
+			return setFilter(s1, s, func(e any, others [][]any) bool {
+				for _, o := range others {
+					if indexOf(e, o) < 0 {
+						return false
+					}
+				}
+				return true
+			})
+		}},
+	},
+	{
+		Category:    setCategory,
+		Syntax:      "difference <s1 []any> ... <sN []any>",
+		Description: []string{"Returns the values of s1 which do not appear in any of the other slices, without duplicates"},
+		Functions: template.FuncMap{"difference": func(s1 any, s ...any) ([]any, error) {
+			return setFilter(s1, s, func(e any, others [][]any) bool {
+				for _, o := range others {
+					if indexOf(e, o) >= 0 {
+						return false
+					}
+				}
+				return true
+			})
+		}},
+	},
+	{
+		Category:    setCategory,
+		Syntax:      "symmetric_difference <s1 []any> <s2 []any>",
+		Description: []string{"Returns the values appearing in exactly one of s1 and s2, without duplicates. Values of s1 come first"},
+		Functions: template.FuncMap{"symmetric_difference": func(s1, s2 any) ([]any, error) {
+			v1, err := toSlice(s1)
+			if err != nil {
+				return nil, err
+			}
+			v2, err := toSlice(s2)
+			if err != nil {
+				return nil, err
+			}
+			ret := []any{}
+			for _, pair := range [][2][]any{{v1, v2}, {v2, v1}} {
+				for _, e := range pair[0] {
+					if indexOf(e, pair[1]) < 0 && indexOf(e, ret) < 0 {
+						ret = append(ret, e)
+					}
+				}
+			}
+			return ret, nil
+		}},
+	},
+	{
+		Category:    setCategory,
+		Syntax:      "contains <v any> <s []any>",
+		Description: []string{"Returns true if s contains v, false otherwise"},
+		Functions: template.FuncMap{"contains": func(e, s any) (bool, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return false, err
+			}
+			return indexOf(e, v) >= 0, nil
+		}},
+	},
+	{
+		Category:    setCategory,
+		Syntax:      "is_subset <s1 []any> <s2 []any>",
+		Description: []string{"Returns true if all the values of s1 appear in s2, false otherwise"},
+		Functions: template.FuncMap{"is_subset": func(s1, s2 any) (bool, error) {
+			v1, err := toSlice(s1)
+			if err != nil {
+				return false, err
+			}
+			v2, err := toSlice(s2)
+			if err != nil {
+				return false, err
+			}
+			for _, e := range v1 {
+				if indexOf(e, v2) < 0 {
+					return false, nil
+				}
+			}
+			return true, nil
+		}},
+	},
+}
+
+// setFilter returns the values of s1, without duplicates, for which keep returns true. keep is given the other
+// slices, converted to []any.
+func setFilter(s1 any, s []any, keep func(e any, others [][]any) bool) ([]any, error) {
+	v1, err := toSlice(s1)
+	if err != nil {
+		return nil, err
+	}
+	others := make([][]any, len(s))
+	for i := range s {
+		if others[i], err = toSlice(s[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := []any{}
+	for _, e := range v1 {
+		if keep(e, others) && indexOf(e, ret) < 0 {
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
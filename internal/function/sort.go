@@ -0,0 +1,233 @@
+package function
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+const sortCategory = "Sorting"
+
+var sortFuncs = []FunctionSet{
+	{
+		Category: sortCategory,
+		Syntax:   "sort[_desc] <s []any>",
+		Description: []string{
+			"Returns a sorted copy of s. Numbers are compared by value and strings in natural order (web2 before web10).",
+			"Values of different types are ordered null, bool, number, string, bytes, list, map. The _desc variant sorts in descending order",
+		},
+		Functions: template.FuncMap{
+			"sort": func(s any) ([]any, error) {
+				return sortSlice(s, nil, false)
+			},
+			"sort_desc": func(s any) ([]any, error) {
+				return sortSlice(s, nil, true)
+			},
+		},
+	},
+	{
+		Category: sortCategory,
+		Syntax:   "sort_by[_desc] <path string> <s []any>",
+		Description: []string{
+			"Returns a copy of s sorted on the value found at path in each element (see filter_path for the path syntax).",
+			"Elements where path does not exist are sorted as null. The sort is stable. The _desc variant sorts in descending order",
+		},
+		Functions: template.FuncMap{
+			"sort_by": func(path string, s any) ([]any, error) {
+				elements, err := parsePath(path)
+				if err != nil {
+					return nil, err
+				}
+				return sortSlice(s, elements, false)
+			},
+			"sort_by_desc": func(path string, s any) ([]any, error) {
+				elements, err := parsePath(path)
+				if err != nil {
+					return nil, err
+				}
+				return sortSlice(s, elements, true)
+			},
+		},
+	},
+	{
+		Category:    sortCategory,
+		Syntax:      "reverse <s []any>",
+		Description: []string{"Returns a copy of s with the elements in reverse order"},
+		Functions: template.FuncMap{"reverse": func(s any) ([]any, error) {
+			v, err := toSlice(s)
+			if err != nil {
+				return nil, err
+			}
+			v = slices.Clone(v)
+			slices.Reverse(v)
+			return v, nil
+		}},
+	},
+	{
+		Category:    sortCategory,
+		Syntax:      "sorted_keys <m map[string]any>",
+		Description: []string{"Returns the keys of m, sorted"},
+		Functions: template.FuncMap{"sorted_keys": func(m any) ([]string, error) {
+			return sortedKeys(m)
+		}},
+	},
+}
+
+// sortSlice returns a sorted copy of s. If path is not nil, elements are compared on the value found at path.
+func sortSlice(s any, path []pathElement, desc bool) ([]any, error) {
+	v, err := toSlice(s)
+	if err != nil {
+		return nil, err
+	}
+	v = slices.Clone(v)
+
+	key := func(e any) any {
+		if path == nil {
+			return e
+		}
+		value, _ := lookupPath(e, path)
+		return value
+	}
+	slices.SortStableFunc(v, func(a, b any) int {
+		if desc {
+			return compareValues(key(b), key(a))
+		}
+		return compareValues(key(a), key(b))
+	})
+	return v, nil
+}
+
+// sortedKeys returns the sorted keys of m, which must be a map with string keys.
+func sortedKeys(m any) ([]string, error) {
+	if m, ok := m.(map[string]any); ok {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		return keys, nil
+	}
+
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("Unsupported type: %T", m)
+	}
+	keys := make([]string, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// typeRanks orders values of different types when comparing them
+var typeRanks = map[string]int{"null": 0, "bool": 1, "number": 2, "string": 3, "bytes": 4, "list": 5, "map": 6}
+
+// compareValues compares a and b, returning -1, 0 or +1. Numbers are compared by value regardless of their Go type,
+// strings in natural order, lists element by element, and maps on their sorted keys then values. Values of
+// different types are ordered according to typeRanks.
+func compareValues(a, b any) int {
+	ta, tb := typeName(a), typeName(b)
+	if ta != tb {
+		ra, oka := typeRanks[ta]
+		rb, okb := typeRanks[tb]
+		if !oka || !okb {
+			return cmp.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b))
+		}
+		return cmp.Compare(ra, rb)
+	}
+
+	switch ta {
+	case "null":
+		return 0
+	case "bool":
+		ba, bb := reflect.ValueOf(a).Bool(), reflect.ValueOf(b).Bool()
+		switch {
+		case ba == bb:
+			return 0
+		case bb:
+			return -1
+		default:
+			return 1
+		}
+	case "number":
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		return cmp.Compare(fa, fb)
+	case "string":
+		return naturalCompare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+	case "bytes":
+		return bytes.Compare(a.(Bytes), b.(Bytes))
+	case "list":
+		la, lb := reflect.ValueOf(a), reflect.ValueOf(b)
+		for i := 0; i < la.Len() && i < lb.Len(); i++ {
+			if c := compareValues(la.Index(i).Interface(), lb.Index(i).Interface()); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(la.Len(), lb.Len())
+	case "map":
+		ka, errA := sortedKeys(a)
+		kb, errB := sortedKeys(b)
+		if errA != nil || errB != nil {
+			return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		}
+		if c := slices.Compare(ka, kb); c != 0 {
+			return c
+		}
+		ma, mb := reflect.ValueOf(a), reflect.ValueOf(b)
+		for _, k := range ka {
+			key := reflect.ValueOf(k)
+			if c := compareValues(ma.MapIndex(key.Convert(ma.Type().Key())).Interface(), mb.MapIndex(key.Convert(mb.Type().Key())).Interface()); c != 0 {
+				return c
+			}
+		}
+		return 0
+	default:
+		return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+// naturalCompare compares a and b like strings.Compare, except that runs of digits are compared by numeric value,
+// so that web2 sorts before web10. Strings which only differ by leading zeros are ordered lexicographically
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return cmp.Compare(a[i], b[j])
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Compare the digit runs, ignoring leading zeros: the longer is greater, or the first differing digit decides
+		si, sj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		da, db := strings.TrimLeft(a[si:i], "0"), strings.TrimLeft(b[sj:j], "0")
+		if c := cmp.Compare(len(da), len(db)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(da, db); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(len(a)-i, len(b)-j); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,89 @@
+package function
+
+import (
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const sshCategory = "SSH"
+
+var sshFuncs = []FunctionSet{
+	{
+		Category: sshCategory,
+		Syntax:   "gen_ssh_keypair [<options map[string]any>]",
+		Description: []string{
+			"Generates an SSH key pair, and returns a map with the private_key (OpenSSH format), public_key (authorized_keys format)",
+			"and fingerprint (SHA256) entries. Assign the result to a variable to use the same key in several places.",
+			"Supports the gen_private_key options, but key_type defaults to ed25519 and key_bits to 3072, and the following one:",
+			"  comment (string): the comment of the key",
+		},
+		Functions: template.FuncMap{"gen_ssh_keypair": func(options ...map[string]any) (map[string]any, error) {
+			if len(options) > 1 {
+				return nil, fmt.Errorf("Invalid number of arguments: %d", len(options))
+			}
+			opts := map[string]any{"key_type": "ed25519", "key_bits": 3072}
+			if len(options) == 1 {
+				if err := checkOptions(options[0], "key_type", "key_bits", "key_curve", "comment"); err != nil {
+					return nil, err
+				}
+				opts = deepMerge(opts, options[0])
+			}
+			comment, err := stringOption(opts, "comment", "")
+			if err != nil {
+				return nil, err
+			}
+			delete(opts, "comment")
+
+			key, err := generateKey(opts)
+			if err != nil {
+				return nil, err
+			}
+			block, err := ssh.MarshalPrivateKey(key, comment)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := ssh.NewPublicKey(key.Public())
+			if err != nil {
+				return nil, err
+			}
+			authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(pub)), "\n")
+			if comment != "" {
+				authorizedKey += " " + comment
+			}
+
+			return map[string]any{
+				"private_key": string(pem.EncodeToMemory(block)),
+				"public_key":  authorizedKey,
+				"fingerprint": ssh.FingerprintSHA256(pub),
+			}, nil
+		}},
+	},
+	{
+		Category: sshCategory,
+		Syntax:   "ssh_fingerprint[_md5] <public_key string>",
+		Description: []string{
+			"Returns the fingerprint of the SSH public key, given in the authorized_keys format, as shown by ssh-keygen -l (SHA256:...).",
+			"The _md5 variant returns the legacy MD5 fingerprint (aa:bb:...)",
+		},
+		Functions: template.FuncMap{
+			"ssh_fingerprint": func(key string) (string, error) {
+				pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+				if err != nil {
+					return "", err
+				}
+				return ssh.FingerprintSHA256(pub), nil
+			},
+			"ssh_fingerprint_md5": func(key string) (string, error) {
+				pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+				if err != nil {
+					return "", err
+				}
+				return ssh.FingerprintLegacyMD5(pub), nil
+			},
+		},
+	},
+}
@@ -125,4 +125,29 @@ var stringFuncs = []FunctionSet{
 			return strings.Join(matches, "_")
 		}},
 	},
+	{
+		Category: stringCategory,
+		Syntax:   "indent|nindent <n int> <s string>",
+		Description: []string{
+			"Indents each non-empty line of s with n spaces. nindent also adds a newline at the beginning of the result,",
+			"which is handy to place a block at a given nesting level: {{.Data.spec | to_yaml | nindent 4}}",
+		},
+		Functions: template.FuncMap{
+			"indent": indent,
+			"nindent": func(n int, s string) string {
+				return "\n" + indent(n, s)
+			},
+		},
+	},
+}
+
+func indent(n int, s string) string {
+	prefix := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,368 @@
+package function
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+	// Embed the time zone database, so that time zones work on systems without one
+	_ "time/tzdata"
+)
+
+const timeCategory = "Time"
+
+// now returns the current time
+var now = time.Now
+
+// SetNow makes the time functions use t as the current time
+func SetNow(t time.Time) {
+	now = func() time.Time { return t }
+}
+
+// namedTimeFormats contains the layouts which can be referred to by name
+var namedTimeFormats = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// strftimeDirectives maps strftime directives to their Go layout
+var strftimeDirectives = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'm': "01",
+	'y': "06",
+	'Y': "2006",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'L': "000",
+	'f': "000000",
+	'p': "PM",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'R': "15:04",
+	'D': "01/02/06",
+	'%': "%",
+}
+
+// defaultTimeLayouts are tried in order when parsing a time without format
+var defaultTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", time.DateTime, time.DateOnly, time.RFC1123Z, time.RFC1123}
+
+var timeUnits = map[string]time.Duration{
+	"nanosecond":  time.Nanosecond,
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+}
+
+var timeFuncs = []FunctionSet{
+	{
+		Category: timeCategory,
+		Syntax:   "now",
+		Description: []string{
+			"Returns the current time, or the time given with the -now flag",
+		},
+		Functions: template.FuncMap{"now": func() time.Time {
+			return now()
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "unix_time [<t time.Time|string|number>]",
+		Description: []string{
+			"Returns t, or the current time, as a number of seconds since the Unix epoch",
+		},
+		Functions: template.FuncMap{"unix_time": func(args ...any) (int64, error) {
+			switch len(args) {
+			case 0:
+				return now().Unix(), nil
+			case 1:
+				t, err := toTime(args[0])
+				return t.Unix(), err
+			default:
+				return 0, fmt.Errorf("Invalid number of arguments: %d", len(args))
+			}
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "format_time <format string> <t time.Time|string|number>",
+		Description: []string{
+			"Formats t. format is either a Go layout (2006-01-02T15:04:05Z07:00), a strftime format (%Y-%m-%dT%H:%M:%S)",
+			"or the name of a predefined format: RFC3339, RFC3339Nano, RFC1123, RFC1123Z, RFC822, RFC822Z, RFC850, ANSIC, UnixDate,",
+			"RubyDate, Kitchen, Stamp, StampMilli, StampMicro, StampNano, DateTime, DateOnly or TimeOnly.",
+			"Strings are parsed as parse_time does, and numbers are a number of seconds since the Unix epoch",
+		},
+		Functions: template.FuncMap{"format_time": func(format string, v any) (string, error) {
+			t, err := toTime(v)
+			if err != nil {
+				return "", err
+			}
+			if layout, ok := namedTimeFormats[format]; ok {
+				return t.Format(layout), nil
+			}
+			if strings.Contains(format, "%") {
+				return strftime(t, format)
+			}
+			return t.Format(format), nil
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "parse_time [<format string>] <s string>",
+		Description: []string{
+			"Parses s using format, which is a Go layout, a strftime format or the name of a predefined format, as for format_time.",
+			"Without format, s must be either RFC3339, \"2006-01-02 15:04:05\", \"2006-01-02\" or RFC1123.",
+			"Times without a time zone are in UTC",
+		},
+		Functions: template.FuncMap{"parse_time": func(args ...any) (time.Time, error) {
+			switch len(args) {
+			case 1:
+				return toTime(args[0])
+			case 2:
+				format, ok := args[0].(string)
+				if !ok {
+					return time.Time{}, fmt.Errorf("Unsupported type: %T", args[0])
+				}
+				s, ok := args[1].(string)
+				if !ok {
+					return time.Time{}, fmt.Errorf("Unsupported type: %T", args[1])
+				}
+				return parseTime(format, s)
+			default:
+				return time.Time{}, fmt.Errorf("Invalid number of arguments: %d", len(args))
+			}
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "add_duration <d time.Duration|string|number> <t time.Time|string|number>",
+		Description: []string{
+			"Returns t plus d, which is a duration as accepted by parse_duration",
+		},
+		Functions: template.FuncMap{"add_duration": func(d any, v any) (time.Time, error) {
+			duration, err := toDuration(d)
+			if err != nil {
+				return time.Time{}, err
+			}
+			t, err := toTime(v)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return t.Add(duration), nil
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "time_diff <from time.Time|string|number> <to time.Time|string|number>",
+		Description: []string{
+			"Returns the duration between from and to, which is negative if to is before from",
+		},
+		Functions: template.FuncMap{"time_diff": func(from any, to any) (time.Duration, error) {
+			f, err := toTime(from)
+			if err != nil {
+				return 0, err
+			}
+			t, err := toTime(to)
+			if err != nil {
+				return 0, err
+			}
+			return t.Sub(f), nil
+		}},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "truncate_time|round_time <unit string> <t time.Time|string|number>",
+		Description: []string{
+			"Rounds t down/to the nearest unit. unit is either nanosecond, microsecond, millisecond, second, minute, hour, day,",
+			"week (starting on Monday), month or year, or a duration (15m). Days, weeks, months and years are in the time zone of t",
+		},
+		Functions: template.FuncMap{
+			"truncate_time": func(unit string, v any) (time.Time, error) {
+				return roundTime(unit, v, false)
+			},
+			"round_time": func(unit string, v any) (time.Time, error) {
+				return roundTime(unit, v, true)
+			},
+		},
+	},
+	{
+		Category: timeCategory,
+		Syntax:   "in_timezone <tz string> <t time.Time|string|number>",
+		Description: []string{
+			"Returns t in the time zone tz, which is either UTC, Local or an IANA time zone name (Europe/Paris)",
+		},
+		Functions: template.FuncMap{"in_timezone": func(tz string, v any) (time.Time, error) {
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("Unknown time zone: %s", tz)
+			}
+			t, err := toTime(v)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return t.In(loc), nil
+		}},
+	},
+}
+
+// toTime converts v to a time. Strings are parsed using the default layouts, and numbers are seconds since the Unix epoch
+func toTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		for _, layout := range defaultTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("Invalid time: %s", v)
+	}
+
+	f, ok := toFloat(v)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("Unsupported type: %T", v)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC(), nil
+}
+
+func parseTime(format string, s string) (time.Time, error) {
+	layout, ok := namedTimeFormats[format]
+	if !ok {
+		layout = format
+		if strings.Contains(format, "%") {
+			var err error
+			if layout, err = strftimeLayout(format); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid time: %s", s)
+	}
+	return t, nil
+}
+
+// strftime formats t according to the strftime format f. Literal text is copied as is
+func strftime(t time.Time, f string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(f); i++ {
+		if f[i] != '%' {
+			sb.WriteByte(f[i])
+			continue
+		}
+		if i++; i == len(f) {
+			return "", fmt.Errorf("Invalid time format: %s", f)
+		}
+		layout, ok := strftimeDirectives[f[i]]
+		if !ok {
+			return "", fmt.Errorf("Unsupported time format directive: %%%c", f[i])
+		}
+		// Go only formats fractional seconds after a dot, so they are formatted here
+		switch f[i] {
+		case '%':
+			sb.WriteByte('%')
+		case 'L':
+			fmt.Fprintf(&sb, "%03d", t.Nanosecond()/1e6)
+		case 'f':
+			fmt.Fprintf(&sb, "%06d", t.Nanosecond()/1e3)
+		default:
+			sb.WriteString(t.Format(layout))
+		}
+	}
+	return sb.String(), nil
+}
+
+// strftimeLayout converts the strftime format f to a Go layout, for parsing
+func strftimeLayout(f string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(f); i++ {
+		if f[i] != '%' {
+			sb.WriteByte(f[i])
+			continue
+		}
+		if i++; i == len(f) {
+			return "", fmt.Errorf("Invalid time format: %s", f)
+		}
+		layout, ok := strftimeDirectives[f[i]]
+		if !ok {
+			return "", fmt.Errorf("Unsupported time format directive: %%%c", f[i])
+		}
+		sb.WriteString(layout)
+	}
+	return sb.String(), nil
+}
+
+func roundTime(unit string, v any, round bool) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var start, end time.Time
+	switch unit {
+	case "day":
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		end = start.AddDate(0, 0, 1)
+	case "week":
+		start = time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+		end = start.AddDate(0, 0, 7)
+	case "month":
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		end = start.AddDate(0, 1, 0)
+	case "year":
+		start = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+		end = start.AddDate(1, 0, 0)
+	default:
+		d, ok := timeUnits[unit]
+		if !ok {
+			if d, err = parseDuration(unit); err != nil || d <= 0 {
+				return time.Time{}, fmt.Errorf("Invalid unit: %s", unit)
+			}
+		}
+		if round {
+			return t.Round(d), nil
+		}
+		return t.Truncate(d), nil
+	}
+
+	if round && t.Sub(start) >= end.Sub(t) {
+		return end, nil
+	}
+	return start, nil
+}
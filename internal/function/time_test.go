@@ -0,0 +1,57 @@
+package function
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftime(t *testing.T) {
+	tm := time.Date(2024, time.March, 5, 14, 7, 9, 123456789, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%a", "Tue"},
+		{"%A", "Tuesday"},
+		{"%b", "Mar"},
+		{"%h", "Mar"},
+		{"%B", "March"},
+		{"%d", "05"},
+		{"%e", " 5"},
+		{"%j", "065"},
+		{"%m", "03"},
+		{"%y", "24"},
+		{"%Y", "2024"},
+		{"%H", "14"},
+		{"%I", "02"},
+		{"%M", "07"},
+		{"%S", "09"},
+		{"%L", "123"},
+		{"%f", "123456"},
+		{"%p", "PM"},
+		{"%z", "+0100"},
+		{"%Z", "CET"},
+		{"%F", "2024-03-05"},
+		{"%T", "14:07:09"},
+		{"%R", "14:07"},
+		{"%D", "03/05/24"},
+		{"%%", "%"},
+		{"%H:%M:%S.%L / %f", "14:07:09.123 / 123456"},
+		{"week 1 of %Y", "week 1 of 2024"},
+	}
+	for _, test := range tests {
+		got, err := strftime(tm, test.format)
+		if err != nil {
+			t.Errorf("strftime(%q): unexpected error: %v", test.format, err)
+		} else if got != test.want {
+			t.Errorf("strftime(%q) = %q, want %q", test.format, got, test.want)
+		}
+	}
+
+	for _, format := range []string{"%", "%Q"} {
+		if _, err := strftime(tm, format); err == nil {
+			t.Errorf("strftime(%q): expected an error", format)
+		}
+	}
+}
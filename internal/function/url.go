@@ -0,0 +1,34 @@
+package function
+
+import (
+	"net/url"
+	"text/template"
+)
+
+const urlCategory = "URL"
+
+var urlFuncs = []FunctionSet{
+	{
+		Category: urlCategory,
+		Syntax:   "url_query_escape|url_path_escape <val string>",
+		Description: []string{
+			"Escapes val so it can be safely placed inside a URL query parameter/path segment.",
+			"url_query_escape encodes spaces as +, url_path_escape as %20",
+		},
+		Functions: template.FuncMap{
+			"url_query_escape": url.QueryEscape,
+			"url_path_escape":  url.PathEscape,
+		},
+	},
+	{
+		Category: urlCategory,
+		Syntax:   "url_query_unescape|url_path_unescape <val string>",
+		Description: []string{
+			"Reverses url_query_escape/url_path_escape. Returns an error if val contains an invalid % sequence",
+		},
+		Functions: template.FuncMap{
+			"url_query_unescape": url.QueryUnescape,
+			"url_path_unescape":  url.PathUnescape,
+		},
+	},
+}
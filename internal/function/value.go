@@ -1,6 +1,7 @@
 package function
 
 import (
+	"reflect"
 	"text/template"
 )
 
@@ -46,3 +47,30 @@ func hasValue(value any) bool {
 		return true
 	}
 }
+
+// toFloat converts any numeric value to a float64. The second return value is false if v is not a number.
+func toFloat(v any) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// equalValues checks whether a and b are equal. Numbers are compared by value regardless of their Go type, and
+// slices and maps are compared recursively.
+func equalValues(a, b any) bool {
+	if typeName(a) == "" || typeName(b) == "" {
+		return reflect.DeepEqual(a, b)
+	}
+	return compareValues(a, b) == 0
+}
@@ -0,0 +1,57 @@
+package function
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const wireguardCategory = "WireGuard"
+
+var wireguardFuncs = []FunctionSet{
+	{
+		Category: wireguardCategory,
+		Syntax:   "gen_wireguard_keypair",
+		Description: []string{
+			"Generates a WireGuard key pair, and returns a map with the Base64 encoded private_key and public_key entries,",
+			"as wg genkey and wg pubkey do. Assign the result to a variable to use the same key in several places",
+		},
+		Functions: template.FuncMap{"gen_wireguard_keypair": func() (map[string]any, error) {
+			data := make([]byte, 32)
+			if _, err := rand.Read(data); err != nil {
+				return nil, err
+			}
+			// Clamp the key as wg genkey does
+			data[0] &= 248
+			data[31] = (data[31] & 127) | 64
+
+			key, err := ecdh.X25519().NewPrivateKey(data)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"private_key": base64.StdEncoding.EncodeToString(key.Bytes()),
+				"public_key":  base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()),
+			}, nil
+		}},
+	},
+	{
+		Category:    wireguardCategory,
+		Syntax:      "wg_pubkey <private_key string>",
+		Description: []string{"Returns the Base64 encoded public key of the Base64 encoded WireGuard private_key, as wg pubkey does"},
+		Functions: template.FuncMap{"wg_pubkey": func(privateKey string) (string, error) {
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+			if err != nil {
+				return "", fmt.Errorf("Invalid WireGuard private key: %w", err)
+			}
+			key, err := ecdh.X25519().NewPrivateKey(data)
+			if err != nil {
+				return "", fmt.Errorf("Invalid WireGuard private key: %w", err)
+			}
+			return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+		}},
+	},
+}
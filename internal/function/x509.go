@@ -0,0 +1,382 @@
+package function
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const x509Category = "Certificates"
+
+var x509Funcs = []FunctionSet{
+	{
+		Category: x509Category,
+		Syntax:   "gen_private_key [<options map[string]any>]",
+		Description: []string{
+			"Generates a private key and returns it PEM encoded (PKCS #8). The following options are supported:",
+			"  key_type (string): rsa, ecdsa or ed25519, defaults to ecdsa",
+			"  key_bits (int): the size of RSA keys, defaults to 2048",
+			"  key_curve (string): the curve of ECDSA keys, P256, P384 or P521, defaults to P256",
+		},
+		Functions: template.FuncMap{"gen_private_key": func(options ...map[string]any) (string, error) {
+			if len(options) > 1 {
+				return "", fmt.Errorf("Invalid number of arguments: %d", len(options))
+			}
+			if len(options) == 0 {
+				options = append(options, map[string]any{})
+			}
+			if err := checkOptions(options[0], "key_type", "key_bits", "key_curve"); err != nil {
+				return "", err
+			}
+			key, err := generateKey(options[0])
+			if err != nil {
+				return "", err
+			}
+			return encodePrivateKey(key)
+		}},
+	},
+	{
+		Category: x509Category,
+		Syntax:   "gen_ca <options map[string]any>",
+		Description: []string{
+			"Generates a self-signed CA certificate and returns a map with the PEM encoded cert and key.",
+			"Supports the gen_private_key options to choose the key, and the following ones:",
+			"  common_name (string): the common name of the subject, required",
+			"  organization (string): the organization of the subject",
+			"  validity_days (int): the validity period in days, defaults to 3650",
+			"  key (string): a PEM encoded private key to use instead of generating one",
+		},
+		Functions: template.FuncMap{"gen_ca": func(options map[string]any) (map[string]any, error) {
+			return generateCert(options, nil, true)
+		}},
+	},
+	{
+		Category: x509Category,
+		Syntax:   "gen_self_signed_cert <options map[string]any>",
+		Description: []string{
+			"Generates a self-signed certificate for TLS servers and clients, and returns a map with the PEM encoded cert and key.",
+			"Supports the gen_ca options (validity_days defaults to 365), and the following ones:",
+			"  dns_names ([]string): the DNS subject alternative names",
+			"  ip_addresses ([]string): the IP subject alternative names",
+			"  email_addresses ([]string): the email subject alternative names",
+			"  is_ca (bool): whether the certificate can sign other certificates, defaults to false",
+		},
+		Functions: template.FuncMap{"gen_self_signed_cert": func(options map[string]any) (map[string]any, error) {
+			return generateCert(options, nil, false)
+		}},
+	},
+	{
+		Category: x509Category,
+		Syntax:   "gen_signed_cert <ca map[string]any> <options map[string]any>",
+		Description: []string{
+			"Generates a certificate signed by ca, which is a map with the PEM encoded cert and key (as returned by gen_ca).",
+			"Returns a map with the PEM encoded cert, key and ca certificate. Supports the same options as gen_self_signed_cert",
+		},
+		Functions: template.FuncMap{"gen_signed_cert": func(ca map[string]any, options map[string]any) (map[string]any, error) {
+			return generateCert(options, ca, false)
+		}},
+	},
+	{
+		Category: x509Category,
+		Syntax:   "parse_cert <cert string|Bytes>",
+		Description: []string{
+			"Parses a PEM or DER encoded certificate, and returns a map with the following entries:",
+			"subject, common_name, issuer, serial, not_before, not_after, dns_names, ip_addresses, email_addresses, is_ca,",
+			"key_type, fingerprint_sha1 and fingerprint_sha256. If cert contains several certificates, the first one is parsed",
+		},
+		Functions: template.FuncMap{"parse_cert": func(v any) (map[string]any, error) {
+			data, err := toBytes(v)
+			if err != nil {
+				return nil, err
+			}
+			cert, err := parseCertificate(data)
+			if err != nil {
+				return nil, err
+			}
+
+			ips := make([]any, len(cert.IPAddresses))
+			for i := range cert.IPAddresses {
+				ips[i] = cert.IPAddresses[i].String()
+			}
+			sha1Sum := sha1.Sum(cert.Raw)
+			sha256Sum := sha256.Sum256(cert.Raw)
+			return map[string]any{
+				"subject":            cert.Subject.String(),
+				"common_name":        cert.Subject.CommonName,
+				"issuer":             cert.Issuer.String(),
+				"serial":             cert.SerialNumber.Text(16),
+				"not_before":         cert.NotBefore,
+				"not_after":          cert.NotAfter,
+				"dns_names":          stringsToSlice(cert.DNSNames),
+				"ip_addresses":       ips,
+				"email_addresses":    stringsToSlice(cert.EmailAddresses),
+				"is_ca":              cert.IsCA,
+				"key_type":           keyTypeName(cert.PublicKey),
+				"fingerprint_sha1":   hex.EncodeToString(sha1Sum[:]),
+				"fingerprint_sha256": hex.EncodeToString(sha256Sum[:]),
+			}, nil
+		}},
+	},
+}
+
+func stringsToSlice(s []string) []any {
+	ret := make([]any, len(s))
+	for i := range s {
+		ret[i] = s[i]
+	}
+	return ret
+}
+
+// stringListOption returns the value of the option k, which must be a slice, as a slice of strings
+func stringListOption(options map[string]any, k string) ([]string, error) {
+	v, ok := options[k]
+	if !ok {
+		return nil, nil
+	}
+	s, err := toSlice(v)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value for option %s: %v", k, v)
+	}
+	ret := make([]string, len(s))
+	for i := range s {
+		if ret[i], ok = s[i].(string); !ok {
+			return nil, fmt.Errorf("Invalid value for option %s: %v", k, v)
+		}
+	}
+	return ret, nil
+}
+
+func generateKey(options map[string]any) (crypto.Signer, error) {
+	keyType, err := stringOption(options, "key_type", "ecdsa")
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyType {
+	case "rsa":
+		bits, err := intOption(options, "key_bits", 2048)
+		if err != nil {
+			return nil, err
+		}
+		if bits < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits long")
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+
+	case "ecdsa":
+		name, err := stringOption(options, "key_curve", "P256")
+		if err != nil {
+			return nil, err
+		}
+		curves := map[string]elliptic.Curve{"P256": elliptic.P256(), "P384": elliptic.P384(), "P521": elliptic.P521()}
+		curve, ok := curves[strings.ToUpper(strings.ReplaceAll(name, "-", ""))]
+		if !ok {
+			return nil, fmt.Errorf("Unsupported curve: %s", name)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+
+	case "ed25519":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+
+	default:
+		return nil, fmt.Errorf("Unsupported key type: %s", keyType)
+	}
+}
+
+func encodePrivateKey(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// parsePrivateKey parses a PEM encoded private key in the PKCS #8, PKCS #1 or SEC 1 format
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		var key any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("Unsupported private key type: %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("No private key found")
+}
+
+// parseCertificate parses the first certificate found in data, which is either PEM or DER encoded
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("No certificate found: %w", err)
+	}
+	return cert, nil
+}
+
+func keyTypeName(key any) string {
+	switch key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return "rsa"
+	case *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		return "ecdsa"
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return "ed25519"
+	default:
+		return fmt.Sprintf("%T", key)
+	}
+}
+
+// generateCert generates a certificate. It is self-signed if ca is nil, and signed by ca otherwise.
+func generateCert(options map[string]any, ca map[string]any, isCA bool) (map[string]any, error) {
+	allowed := []string{"key_type", "key_bits", "key_curve", "common_name", "organization", "validity_days", "key"}
+	if !isCA {
+		allowed = append(allowed, "dns_names", "ip_addresses", "email_addresses", "is_ca")
+	}
+	if err := checkOptions(options, allowed...); err != nil {
+		return nil, err
+	}
+
+	commonName, err := stringOption(options, "common_name", "")
+	if err != nil {
+		return nil, err
+	}
+	organization, err := stringOption(options, "organization", "")
+	if err != nil {
+		return nil, err
+	}
+	defaultValidity := 365
+	if isCA {
+		defaultValidity = 3650
+	}
+	validity, err := intOption(options, "validity_days", defaultValidity)
+	if err != nil {
+		return nil, err
+	}
+	if isCA && commonName == "" {
+		return nil, fmt.Errorf("Missing option: common_name")
+	}
+	if v, ok := options["is_ca"]; ok {
+		if isCA, ok = v.(bool); !ok {
+			return nil, fmt.Errorf("Invalid value for option is_ca: %v", v)
+		}
+	}
+
+	var key crypto.Signer
+	keyPEM, err := stringOption(options, "key", "")
+	if err != nil {
+		return nil, err
+	}
+	if keyPEM != "" {
+		key, err = parsePrivateKey([]byte(keyPEM))
+	} else {
+		key, err = generateKey(options)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	notBefore := now().Add(-5 * time.Minute).Truncate(time.Second)
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(0, 0, validity),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if organization != "" {
+		tmpl.Subject.Organization = []string{organization}
+	}
+	if isCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	} else {
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		if _, ok := key.(*rsa.PrivateKey); ok {
+			tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+	if tmpl.DNSNames, err = stringListOption(options, "dns_names"); err != nil {
+		return nil, err
+	}
+	if tmpl.EmailAddresses, err = stringListOption(options, "email_addresses"); err != nil {
+		return nil, err
+	}
+	ips, err := stringListOption(options, "ip_addresses")
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("Invalid IP address: %s", s)
+		}
+		tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+	}
+
+	parent, signer := tmpl, key
+	ret := map[string]any{}
+	if ca != nil {
+		caCertPEM, _ := ca["cert"].(string)
+		caKeyPEM, _ := ca["key"].(string)
+		if parent, err = parseCertificate([]byte(caCertPEM)); err != nil {
+			return nil, fmt.Errorf("Invalid CA certificate: %w", err)
+		}
+		if signer, err = parsePrivateKey([]byte(caKeyPEM)); err != nil {
+			return nil, fmt.Errorf("Invalid CA key: %w", err)
+		}
+		if !parent.IsCA {
+			return nil, fmt.Errorf("Invalid CA certificate: not a CA")
+		}
+		ret["ca"] = caCertPEM
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	if ret["key"], err = encodePrivateKey(key); err != nil {
+		return nil, err
+	}
+	ret["cert"] = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return ret, nil
+}